@@ -2,90 +2,270 @@ package assets
 
 import (
 	"embed"
+	"errors"
+	"fmt"
 	"image"
 	_ "image/png"
 	"io"
 	"io/fs"
+	"path"
+	"slices"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/audio/vorbis"
 )
 
 //go:embed *
-var assets embed.FS
-
-var (
-	PlayerSprite       = mustLoadImage("player.png")
-	CanonSprite        = mustLoadImage("canon_simple.png")
-	MissleSprite       = mustLoadImage("missle1.png")
-	MeteorSprites      = mustLoadImages("meteors/*.png")
-	CanonShootBytes    = mustLoadOgg("sfx/canon_shoot.ogg")
-	PlayerHitBytes     = mustLoadOgg("sfx/player_hit.ogg")
-	MeteorExplodeBytes = mustLoadOgg("sfx/meteor_explode.ogg")
-	SpaceAmbientWav    = mustLoadFile("music/spaceambient.wav")
-)
+var Embedded embed.FS
 
 const SampleRate = 44100
 
-func mustLoadImage(name string) *ebiten.Image {
-	f, err := assets.Open(name)
-	if err != nil {
-		panic(err)
+type Assets struct {
+	FS                  fs.FS // Files streamed while playing, like music
+	PlayerSprite        *ebiten.Image
+	CanonSprite         *ebiten.Image
+	MissleSprite        *ebiten.Image
+	MeteorSprites       []*ebiten.Image
+	MeteorNames         []string // Files MeteorSprites were loaded from, empty for a placeholder
+	CanonShootSounds    [][]byte
+	PlayerHitSounds     [][]byte
+	MeteorExplodeSounds [][]byte
+	Sheets              map[string]*SpriteSheet // Animated sprites by manifest name
+	Warnings            []error                 // Problems the game runs fine with, like missing sounds
+}
+
+// sprites and soundGroups tie asset files to fields they are loaded to.
+var sprites = []struct {
+	path  string
+	field func(a *Assets) **ebiten.Image
+}{
+	{"player.png", func(a *Assets) **ebiten.Image { return &a.PlayerSprite }},
+	{"canon_simple.png", func(a *Assets) **ebiten.Image { return &a.CanonSprite }},
+	{"missle1.png", func(a *Assets) **ebiten.Image { return &a.MissleSprite }},
+}
+
+const meteorSprites = "meteors/*.png"
+
+var soundGroups = []struct {
+	pattern string
+	field   func(a *Assets) *[][]byte
+}{
+	{"sfx/canon_shoot*.ogg", func(a *Assets) *[][]byte { return &a.CanonShootSounds }},
+	{"sfx/player_hit*.ogg", func(a *Assets) *[][]byte { return &a.PlayerHitSounds }},
+	{"sfx/meteor_explode*.ogg", func(a *Assets) *[][]byte { return &a.MeteorExplodeSounds }},
+}
+
+// Load reads every asset from fsys. Sprites are required: all sprite problems
+// are joined in the returned error. Sounds are optional, their problems end up
+// in Warnings. Development builds (-tags dev) replace broken sprites and
+// sounds with placeholders and report them as warnings too.
+func Load(fsys fs.FS) (*Assets, error) {
+	l := &loader{fsys: fsys}
+	l.atlases()
+	a := &Assets{FS: fsys}
+	for _, sprite := range sprites {
+		*sprite.field(a) = l.image(sprite.path)
 	}
-	defer f.Close()
+	a.MeteorSprites, a.MeteorNames = l.images(meteorSprites)
+	for _, group := range soundGroups {
+		*group.field(a) = l.oggs(group.pattern)
+	}
+	a.Sheets = l.sheets()
+	a.Warnings = l.warnings
+	if err := errors.Join(l.errors...); err != nil {
+		return a, fmt.Errorf("assets load failed:\n%w", err)
+	}
+	return a, nil
+}
 
-	img, _, err := image.Decode(f)
-	if err != nil {
-		panic(err)
+// IsMissing reports whether every problem in a Load error is a missing file,
+// as opposed to a broken one.
+func IsMissing(err error) bool {
+	switch e := err.(type) {
+	case nil:
+		return false
+	case interface{ Unwrap() []error }:
+		for _, err := range e.Unwrap() {
+			if !IsMissing(err) {
+				return false
+			}
+		}
+		return true
+	case interface{ Unwrap() error }:
+		if next := e.Unwrap(); next != nil {
+			return IsMissing(next)
+		}
+	}
+	return errors.Is(err, fs.ErrNotExist)
+}
+
+// ReloadImage reloads a sprite after its file changed. Old is the sprite being
+// replaced, nil for a new meteor sprite. Img is nil when the file is not a sprite.
+func (a *Assets) ReloadImage(name string) (old, img *ebiten.Image, err error) {
+	for _, sprite := range sprites {
+		if sprite.path != name {
+			continue
+		}
+		if img, err = loadImage(a.FS, name); err != nil {
+			return nil, nil, err
+		}
+		field := sprite.field(a)
+		old, *field = *field, img
+		return old, img, nil
 	}
 
-	return ebiten.NewImageFromImage(img)
+	if ok, _ := path.Match(meteorSprites, name); !ok {
+		return nil, nil, nil
+	}
+	if img, err = loadImage(a.FS, name); err != nil {
+		return nil, nil, err
+	}
+	if i := slices.Index(a.MeteorNames, name); i >= 0 {
+		old, a.MeteorSprites[i] = a.MeteorSprites[i], img
+	} else {
+		a.MeteorSprites = append(a.MeteorSprites, img)
+		a.MeteorNames = append(a.MeteorNames, name)
+	}
+	return old, img, nil
 }
 
-func mustLoadImages(path string) []*ebiten.Image {
-	matches, err := fs.Glob(assets, path)
+// ReloadSounds reloads the group of sound variants a changed file belongs to.
+// It reports false when the file is not a sound.
+func (a *Assets) ReloadSounds(name string) (ok bool, err error) {
+	for _, group := range soundGroups {
+		if match, _ := path.Match(group.pattern, name); !match {
+			continue
+		}
+		l := &loader{fsys: a.FS}
+		variants := l.oggs(group.pattern)
+		if len(variants) > 0 {
+			*group.field(a) = variants
+		}
+		return true, errors.Join(l.warnings...)
+	}
+	return false, nil
+}
+
+type loader struct {
+	fsys     fs.FS
+	atlas    map[string]*ebiten.Image // Atlas frames by the path of the file they were packed from
+	errors   []error
+	warnings []error
+}
+
+func (l *loader) image(name string) *ebiten.Image {
+	if img, ok := l.atlas[name]; ok && !Overridden(l.fsys, name) {
+		return img
+	}
+	img, err := loadImage(l.fsys, name)
 	if err != nil {
-		panic(err)
+		if !Placeholders {
+			l.errors = append(l.errors, err)
+			return nil
+		}
+		l.warnings = append(l.warnings, fmt.Errorf("%w, placeholder used", err))
+		return PlaceholderImage(64, 64)
+	}
+	return img
+}
+
+// images loads every sprite matching path, returning the files they came from too.
+func (l *loader) images(path string) ([]*ebiten.Image, []string) {
+	matches, err := fs.Glob(l.fsys, path)
+	if err == nil {
+		matches = append(matches, l.atlasGlob(path)...)
+		slices.Sort(matches)
+		matches = slices.Compact(matches)
+	}
+	if err == nil && len(matches) == 0 {
+		err = fs.ErrNotExist
+	}
+	if err != nil {
+		err = fmt.Errorf("%s: %w", path, err)
+		if !Placeholders {
+			l.errors = append(l.errors, err)
+			return nil, nil
+		}
+		l.warnings = append(l.warnings, fmt.Errorf("%w, placeholder used", err))
+		return []*ebiten.Image{PlaceholderImage(128, 128)}, []string{""}
 	}
 
 	images := make([]*ebiten.Image, len(matches))
 	for i, match := range matches {
-		images[i] = mustLoadImage(match)
+		images[i] = l.image(match)
 	}
-
-	return images
+	return images, matches
 }
 
-func mustLoadOgg(name string) (b []byte) {
-	f, err := assets.Open(name)
+func (l *loader) oggs(path string) [][]byte {
+	matches, err := fs.Glob(l.fsys, path)
+	if err == nil && len(matches) == 0 {
+		err = fs.ErrNotExist
+	}
 	if err != nil {
-		panic(err)
+		l.warnSound(fmt.Errorf("%s: %w", path, err))
+		return l.placeholderSounds()
 	}
-	defer f.Close()
 
-	s, err := vorbis.DecodeWithSampleRate(SampleRate, f)
+	var variants [][]byte
+	for _, match := range matches {
+		b, err := loadOgg(l.fsys, match)
+		if err != nil {
+			l.warnSound(err)
+			continue
+		}
+		variants = append(variants, b)
+	}
+	if len(variants) == 0 {
+		return l.placeholderSounds()
+	}
+	return variants
+}
+
+func (l *loader) warnSound(err error) {
+	if Placeholders {
+		err = fmt.Errorf("%w, placeholder used", err)
+	}
+	l.warnings = append(l.warnings, err)
+}
+
+func (l *loader) placeholderSounds() [][]byte {
+	if !Placeholders {
+		return nil
+	}
+	return [][]byte{PlaceholderSound()}
+}
+
+func loadImage(fsys fs.FS, name string) (*ebiten.Image, error) {
+	f, err := fsys.Open(name)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-	b, err = io.ReadAll(s)
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("%s: decode error: %w", name, err)
 	}
 
-	return b
+	return ebiten.NewImageFromImage(img), nil
 }
 
-func mustLoadFile(name string) (b []byte) {
-	f, err := assets.Open(name)
+func loadOgg(fsys fs.FS, name string) ([]byte, error) {
+	f, err := fsys.Open(name)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 	defer f.Close()
 
-	b, err = io.ReadAll(f)
+	s, err := vorbis.DecodeWithSampleRate(SampleRate, f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: decode error: %w", name, err)
+	}
+	b, err := io.ReadAll(s)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("%s: read error: %w", name, err)
 	}
 
-	return b
+	return b, nil
 }
@@ -0,0 +1,41 @@
+package assets
+
+import (
+	"io/fs"
+	"path"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Atlases are sprite sheets made by cmd/atlaspack, one image holding many
+// sprites. Their frames are named after the files they were packed from, like
+// meteors/spaceMeteors_001.png, and are used instead of those files: sprites
+// share one texture and only one image is decoded. A mod file replacing a
+// packed one wins over its frame, at start as well as on hot reload.
+const atlasManifests = "atlases/*.json"
+
+func (l *loader) atlases() {
+	l.atlas = map[string]*ebiten.Image{}
+	matches, _ := fs.Glob(l.fsys, atlasManifests)
+	for _, match := range matches {
+		s, err := LoadSpriteSheet(l.fsys, match)
+		if err != nil {
+			l.warnings = append(l.warnings, err)
+			continue
+		}
+		for name, frame := range s.Frames {
+			l.atlas[name] = frame
+		}
+	}
+}
+
+// atlasGlob returns names of atlas frames matching pattern.
+func (l *loader) atlasGlob(pattern string) []string {
+	var matches []string
+	for name := range l.atlas {
+		if ok, _ := path.Match(pattern, name); ok {
+			matches = append(matches, name)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,101 @@
+package assets
+
+import (
+	"errors"
+	"io/fs"
+	"slices"
+	"strings"
+	"time"
+)
+
+type overlayFS struct {
+	top, base fs.FS
+}
+
+// Overlay returns a file system where files of top, like a mod directory,
+// replace files of base with the same name. Directories are merged.
+func Overlay(top, base fs.FS) fs.FS {
+	return overlayFS{top: top, base: base}
+}
+
+func (o overlayFS) Open(name string) (fs.File, error) {
+	f, err := o.top.Open(name)
+	if err == nil {
+		return f, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return o.base.Open(name)
+}
+
+// Overridden reports whether fsys is an overlay whose top provides name.
+func Overridden(fsys fs.FS, name string) bool {
+	o, ok := fsys.(overlayFS)
+	if !ok {
+		return false
+	}
+	_, err := fs.Stat(o.top, name)
+	return err == nil
+}
+
+func (o overlayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	top, topErr := fs.ReadDir(o.top, name)
+	base, baseErr := fs.ReadDir(o.base, name)
+	if topErr != nil && baseErr != nil {
+		return nil, baseErr
+	}
+
+	entries := top
+	for _, e := range base {
+		if !slices.ContainsFunc(top, func(t fs.DirEntry) bool { return t.Name() == e.Name() }) {
+			entries = append(entries, e)
+		}
+	}
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int { return strings.Compare(a.Name(), b.Name()) })
+	return entries, nil
+}
+
+// Watcher polls a file system for files being created, changed or removed.
+type Watcher struct {
+	FS     fs.FS
+	mtimes map[string]time.Time
+}
+
+func NewWatcher(fsys fs.FS) *Watcher {
+	w := &Watcher{FS: fsys}
+	w.mtimes = w.scan()
+	return w
+}
+
+// Poll returns files changed since the previous poll.
+func (w *Watcher) Poll() (changed []string) {
+	mtimes := w.scan()
+	for name, mtime := range mtimes {
+		if prev, ok := w.mtimes[name]; !ok || !prev.Equal(mtime) {
+			changed = append(changed, name)
+		}
+	}
+	for name := range w.mtimes {
+		if _, ok := mtimes[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	w.mtimes = mtimes
+	slices.Sort(changed)
+	return changed
+}
+
+func (w *Watcher) scan() map[string]time.Time {
+	mtimes := map[string]time.Time{}
+	fs.WalkDir(w.FS, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			mtimes[name] = info.ModTime()
+		}
+		return nil
+	})
+	return mtimes
+}
@@ -0,0 +1,43 @@
+package assets
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+var placeholderColors = [2]color.Color{
+	color.RGBA{R: 255, B: 255, A: 255},
+	color.RGBA{A: 255},
+}
+
+// PlaceholderImage returns a magenta checkerboard that is hard to miss on screen.
+func PlaceholderImage(w, h int) *ebiten.Image {
+	const cell = 8
+	img := ebiten.NewImage(w, h)
+	for y := 0; y < h; y += cell {
+		for x := 0; x < w; x += cell {
+			img.SubImage(image.Rect(x, y, x+cell, y+cell)).(*ebiten.Image).Fill(placeholderColors[(x/cell+y/cell)%2])
+		}
+	}
+	return img
+}
+
+// PlaceholderSound returns a short beep as 16-bit stereo PCM.
+func PlaceholderSound() []byte {
+	const (
+		frequency = 880
+		duration  = 0.1
+	)
+	frames := int(duration * SampleRate)
+	b := make([]byte, 4*frames)
+	for i := 0; i < frames; i++ {
+		fade := 1 - float64(i)/float64(frames)
+		v := uint16(int16(0.3 * fade * math.MaxInt16 * math.Sin(2*math.Pi*frequency*float64(i)/SampleRate)))
+		b[4*i], b[4*i+1] = byte(v), byte(v>>8)
+		b[4*i+2], b[4*i+3] = byte(v), byte(v>>8)
+	}
+	return b
+}
@@ -0,0 +1,6 @@
+//go:build !dev
+
+package assets
+
+// Placeholders replace broken or missing assets in development builds.
+const Placeholders = false
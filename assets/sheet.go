@@ -0,0 +1,157 @@
+package assets
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+type LoopMode int
+
+const (
+	LoopOnce     LoopMode = iota // Stop on the last frame
+	LoopForward                  // Start over after the last frame
+	LoopPingPong                 // Play backwards after the last frame, then forwards again
+)
+
+func (m *LoopMode) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	switch s {
+	case "", "once":
+		*m = LoopOnce
+	case "loop":
+		*m = LoopForward
+	case "pingpong":
+		*m = LoopPingPong
+	default:
+		return fmt.Errorf("unknown loop mode %q", s)
+	}
+	return nil
+}
+
+type Frame struct {
+	Image    *ebiten.Image
+	Duration time.Duration
+}
+
+type Clip struct {
+	Name   string
+	Frames []Frame
+	Loop   LoopMode
+}
+
+// SpriteSheet is one image holding many sprites, cut into named frames which
+// named animation clips are made of.
+type SpriteSheet struct {
+	Image  *ebiten.Image
+	Frames map[string]*ebiten.Image
+	Clips  map[string]*Clip
+}
+
+func (s *SpriteSheet) Clip(name string) *Clip {
+	if s == nil {
+		return nil
+	}
+	return s.Clips[name]
+}
+
+// sheetManifest is the JSON file describing a sprite sheet:
+//
+//	{
+//	  "image": "ship.png",
+//	  "frames": {"idle": {"x": 0, "y": 0, "w": 101, "h": 74}, ...},
+//	  "clips": {"bank_left": {"loop": "once", "frames": [{"frame": "idle", "ms": 40}, ...]}}
+//	}
+type sheetManifest struct {
+	Image  string `json:"image"` // Relative to the manifest
+	Frames map[string]struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+		W int `json:"w"`
+		H int `json:"h"`
+	} `json:"frames"`
+	Clips map[string]struct {
+		Loop   LoopMode `json:"loop"`
+		Frames []struct {
+			Frame string `json:"frame"`
+			MS    int    `json:"ms"`
+		} `json:"frames"`
+	} `json:"clips"`
+}
+
+// DefaultFrameDuration is used for clip frames without a duration.
+const DefaultFrameDuration = 100 * time.Millisecond
+
+func LoadSpriteSheet(fsys fs.FS, manifest string) (*SpriteSheet, error) {
+	b, err := fs.ReadFile(fsys, manifest)
+	if err != nil {
+		return nil, err
+	}
+	var m sheetManifest
+	if err = json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("%s: %w", manifest, err)
+	}
+
+	img, err := loadImage(fsys, path.Join(path.Dir(manifest), m.Image))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", manifest, err)
+	}
+	s := &SpriteSheet{
+		Image:  img,
+		Frames: map[string]*ebiten.Image{},
+		Clips:  map[string]*Clip{},
+	}
+	for name, f := range m.Frames {
+		r := image.Rect(f.X, f.Y, f.X+f.W, f.Y+f.H)
+		if !r.In(img.Bounds()) || r.Empty() {
+			return nil, fmt.Errorf("%s: frame %s %v is outside of image %v", manifest, name, r, img.Bounds())
+		}
+		s.Frames[name] = img.SubImage(r).(*ebiten.Image)
+	}
+	for name, c := range m.Clips {
+		clip := &Clip{Name: name, Loop: c.Loop}
+		for _, f := range c.Frames {
+			frame, ok := s.Frames[f.Frame]
+			if !ok {
+				return nil, fmt.Errorf("%s: clip %s uses unknown frame %s", manifest, name, f.Frame)
+			}
+			d := time.Duration(f.MS) * time.Millisecond
+			if d <= 0 {
+				d = DefaultFrameDuration
+			}
+			clip.Frames = append(clip.Frames, Frame{Image: frame, Duration: d})
+		}
+		if len(clip.Frames) == 0 {
+			return nil, fmt.Errorf("%s: clip %s has no frames", manifest, name)
+		}
+		s.Clips[name] = clip
+	}
+	return s, nil
+}
+
+const sheetManifests = "sheets/*.json"
+
+// sheets loads every sprite sheet. Sheets are optional: sprites without
+// animations are used when there are none.
+func (l *loader) sheets() map[string]*SpriteSheet {
+	sheets := map[string]*SpriteSheet{}
+	matches, _ := fs.Glob(l.fsys, sheetManifests)
+	for _, match := range matches {
+		s, err := LoadSpriteSheet(l.fsys, match)
+		if err != nil {
+			l.warnings = append(l.warnings, err)
+			continue
+		}
+		sheets[strings.TrimSuffix(path.Base(match), ".json")] = s
+	}
+	return sheets
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// rect and manifest match the sprite sheet manifest read by assets.LoadSpriteSheet.
+type rect struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type manifest struct {
+	Image  string          `json:"image"`
+	Frames map[string]rect `json:"frames"`
+}
+
+type sprite struct {
+	name string
+	img  image.Image
+	rect rect
+}
+
+func main() {
+	dir := flag.String("dir", "", "directory with sprites to pack")
+	pattern := flag.String("pattern", "*.png", "sprite file name pattern")
+	prefix := flag.String("prefix", "", "frame name prefix, the base name of -dir followed by / by default")
+	out := flag.String("out", "", "output path without extension, .png and .json files are written")
+	padding := flag.Int("padding", 1, "transparent pixels between sprites")
+	maxWidth := flag.Int("max-width", 4096, "atlas width limit")
+	flag.Parse()
+
+	if *dir == "" || *out == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *prefix == "" {
+		*prefix = filepath.Base(*dir) + "/"
+	}
+
+	files, err := filepath.Glob(filepath.Join(*dir, *pattern))
+	if err != nil {
+		log.Fatalf("pattern error: %v", err)
+	}
+	if len(files) == 0 {
+		log.Fatalf("no sprites match %s", filepath.Join(*dir, *pattern))
+	}
+
+	sprites := make([]*sprite, len(files))
+	for i, file := range files {
+		img, err := decode(file)
+		if err != nil {
+			log.Fatalf("%s: %v", file, err)
+		}
+		sprites[i] = &sprite{name: *prefix + filepath.Base(file), img: img}
+	}
+
+	w, h := pack(sprites, *padding, *maxWidth)
+	atlas := image.NewNRGBA(image.Rect(0, 0, w, h))
+	m := manifest{Image: filepath.Base(*out) + ".png", Frames: map[string]rect{}}
+	for _, s := range sprites {
+		r := image.Rect(s.rect.X, s.rect.Y, s.rect.X+s.rect.W, s.rect.Y+s.rect.H)
+		draw.Draw(atlas, r, s.img, s.img.Bounds().Min, draw.Src)
+		m.Frames[s.name] = s.rect
+	}
+
+	if err = write(*out, atlas, m); err != nil {
+		log.Fatalf("atlas write error: %v", err)
+	}
+	log.Printf("packed %d sprites into %dx%d %s.png", len(sprites), w, h, *out)
+}
+
+// pack places sprites on shelves, tallest first, and returns the atlas size.
+// The atlas width is the power of two closest to a square atlas.
+func pack(sprites []*sprite, padding, maxWidth int) (w, h int) {
+	area, widest := 0, 0
+	for _, s := range sprites {
+		b := s.img.Bounds()
+		area += (b.Dx() + padding) * (b.Dy() + padding)
+		widest = max(widest, b.Dx()+padding)
+	}
+	w = 1 << int(math.Ceil(math.Log2(math.Sqrt(float64(area)))))
+	w = max(min(w, maxWidth), widest)
+
+	sort.SliceStable(sprites, func(i, j int) bool {
+		return sprites[i].img.Bounds().Dy() > sprites[j].img.Bounds().Dy()
+	})
+	x, y, shelf := 0, 0, 0
+	for _, s := range sprites {
+		b := s.img.Bounds()
+		if x+b.Dx() > w {
+			x, y, shelf = 0, y+shelf, 0
+		}
+		s.rect = rect{X: x, Y: y, W: b.Dx(), H: b.Dy()}
+		x += b.Dx() + padding
+		shelf = max(shelf, b.Dy()+padding)
+	}
+	return w, y + shelf
+}
+
+func decode(file string) (image.Image, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("decode error: %w", err)
+	}
+	return img, nil
+}
+
+func write(out string, atlas image.Image, m manifest) error {
+	if err := os.MkdirAll(filepath.Dir(out), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(out + ".png")
+	if err != nil {
+		return err
+	}
+	if err = png.Encode(f, atlas); err != nil {
+		f.Close()
+		return fmt.Errorf("png encode error: %w", err)
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(out+".json", append(b, '\n'), 0o644)
+}
@@ -1,22 +1,138 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io/fs"
 	"log"
+	"os"
+	"os/exec"
 
 	"github.com/hajimehoshi/ebiten/v2"
 
+	"github.com/mxpaul/meteorshooter/assets"
 	"github.com/mxpaul/meteorshooter/game"
+	"github.com/mxpaul/meteorshooter/sound"
 )
 
+// audioProbeEnv makes the binary only check the audio device and exit.
+const audioProbeEnv = "METEORSHOOTER_AUDIO_PROBE"
+
 func main() {
-	g := game.NewGame()
+	if os.Getenv(audioProbeEnv) != "" {
+		if err := sound.ProbeDevice(assets.SampleRate); err != nil {
+			fmt.Fprint(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	flight := flag.String("flight", "arcade", "ship flight model: arcade or inertial")
+	wrap := flag.Bool("wrap", false, "wrap-around playfield")
+	world := flag.String("world", "", "playfield size WIDTHxHEIGHT in world units, defaults to the view size")
+	resolution := flag.String("resolution", "", "drawing resolution WIDTHxHEIGHT, defaults to 1600x1200")
+	scale := flag.String("scale", "letterbox", "how the picture fits the window: letterbox, stretch or integer")
+	mute := flag.Bool("mute", false, "run without opening an audio device")
+	mods := flag.String("mods", "", "directory with replacement sprites, sounds and music, reloaded on change")
+	stars := flag.Float64("stars", 1, "background star and nebula density, lower it on slow machines, 0 turns the background off")
+	neon := flag.Bool("neon", false, "draw glowing vector outlines instead of sprites")
+	shake := flag.Float64("shake", 1, "screen shake strength, 0 turns it off")
+	kick := flag.Float64("kick", 1, "camera kick on firing strength, 0 turns it off")
+	noHitStop := flag.Bool("no-hitstop", false, "no freeze frames on hits and big kills")
+	calm := flag.Bool("calm", false, "turn off screen shake, camera kick and freeze frames, for motion-sensitive players")
+	meteorSprites := flag.Bool("meteor-sprites", false, "draw meteors with sprites from assets instead of generating them")
+	tuning := flag.String("tuning", "tuning.json", "tunables file, loaded at start and written by save")
+	flag.Parse()
+
+	opts := game.Options{Wrap: *wrap, Mute: *mute, Mods: *mods, Stars: *stars, MeteorSprites: *meteorSprites, Neon: *neon}
+	opts.Shake, opts.Kick, opts.NoHitStop = *shake, *kick, *noHitStop
+	opts.Tuning = *tuning
+	if *calm {
+		opts.Shake, opts.Kick, opts.NoHitStop = 0, 0, true
+	}
+	var err error
+	if opts.Flight, err = game.ParseFlightMode(*flight); err != nil {
+		log.Fatalf("bad -flight: %v", err)
+	}
+	if opts.Scale, err = game.ParseScaleMode(*scale); err != nil {
+		log.Fatalf("bad -scale: %v", err)
+	}
+	if *resolution != "" {
+		if _, err = fmt.Sscanf(*resolution, "%dx%d", &opts.Resolution.Width, &opts.Resolution.Height); err != nil {
+			log.Fatalf("bad -resolution %q: %v", *resolution, err)
+		}
+	}
+	if *world != "" {
+		if _, err = fmt.Sscanf(*world, "%dx%d", &opts.World.Width, &opts.World.Height); err != nil {
+			log.Fatalf("bad -world %q: %v", *world, err)
+		}
+	}
+
+	// The audio probe runs while assets load and the game is set up, audio
+	// only starts on the first update
+	probe := make(chan error, 1)
+	if opts.Mute {
+		probe <- nil
+	} else {
+		go func() { probe <- probeAudio() }()
+	}
+
+	var fsys fs.FS = assets.Embedded
+	if *mods != "" {
+		fsys = assets.Overlay(os.DirFS(*mods), assets.Embedded)
+	}
+	a, err := assets.Load(fsys)
+	for _, w := range a.Warnings {
+		log.Printf("WARNING: %v", w)
+	}
+	if assets.IsMissing(err) {
+		log.Printf("WARNING: %v", err)
+		log.Printf("WARNING: sprites missing, falling back to neon rendering")
+		game.NeonFallback(a)
+		opts.Neon = true
+	} else if err != nil {
+		log.Fatalf("%v", err)
+	}
+	if len(a.MeteorSprites) == 0 {
+		opts.MeteorSprites = false
+	}
+
+	g := game.NewGame(a, opts)
+	if err = <-probe; err != nil {
+		log.Printf("WARNING: audio device failed, playing without sound: %v", err)
+		g.Mute = true
+	}
 
 	ebiten.SetWindowTitle("Meteor shooter")
 	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
 
-	err := ebiten.RunGame(g)
+	err = ebiten.RunGame(g)
 	if err != nil {
 		log.Fatalf("RunGame error: %v", err)
 	}
 	log.Printf("missle count: %d; meteor count: %d", len(g.Missle), len(g.Meteor))
 }
+
+// probeAudio checks the audio device in a child process, so a failing device
+// leaves this process free to run silently.
+func probeAudio() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("executable lookup error: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*sound.ProbeTimeout)
+	defer cancel()
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, exe)
+	cmd.Env = append(os.Environ(), audioProbeEnv+"=1")
+	cmd.Stderr = &out
+	if err = cmd.Run(); err != nil {
+		if out.Len() > 0 {
+			return fmt.Errorf("%s", out.Bytes())
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mxpaul/meteorshooter/synth"
+)
+
+func main() {
+	preset := flag.String("preset", "", "preset to render: "+strings.Join(synth.PresetNames(), ", ")+" or all")
+	params := flag.String("params", "", "JSON file with synth parameters, used instead of -preset")
+	seed := flag.Int64("seed", 1, "random seed for presets")
+	count := flag.Int("n", 1, "variants to render per preset")
+	rate := flag.Int("rate", 44100, "sample rate")
+	dir := flag.String("dir", ".", "output directory")
+	dump := flag.Bool("dump", false, "also write parameters of every rendered sound as JSON next to it")
+	flag.Parse()
+
+	sounds := map[string]synth.Params{}
+	switch {
+	case *params != "":
+		b, err := os.ReadFile(*params)
+		if err != nil {
+			log.Fatalf("params read error: %v", err)
+		}
+		p := synth.NewParams()
+		if err = json.Unmarshal(b, &p); err != nil {
+			log.Fatalf("params decode error: %v", err)
+		}
+		sounds[strings.TrimSuffix(filepath.Base(*params), filepath.Ext(*params))] = p
+	case *preset != "":
+		names := []string{*preset}
+		if *preset == "all" {
+			names = synth.PresetNames()
+		}
+		r := rand.New(rand.NewSource(*seed))
+		for _, name := range names {
+			newParams, ok := synth.Presets[name]
+			if !ok {
+				log.Fatalf("unknown preset %q", name)
+			}
+			for i := 0; i < *count; i++ {
+				file := name
+				if *count > 1 {
+					file = fmt.Sprintf("%s_%d", name, i+1)
+				}
+				sounds[file] = newParams(r)
+			}
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	for name, p := range sounds {
+		if err := render(filepath.Join(*dir, name), p, *rate, *dump); err != nil {
+			log.Fatalf("%s: %v", name, err)
+		}
+	}
+}
+
+func render(path string, p synth.Params, rate int, dump bool) error {
+	samples, err := p.Render(rate)
+	if err != nil {
+		return fmt.Errorf("bad params: %w", err)
+	}
+	f, err := os.Create(path + ".wav")
+	if err != nil {
+		return err
+	}
+	if err = synth.WriteWAV(f, samples, rate); err != nil {
+		f.Close()
+		return fmt.Errorf("wav write error: %w", err)
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	log.Printf("%s.wav: %.2fs %s", path, p.Duration(), p.Wave)
+
+	if !dump {
+		return nil
+	}
+	b, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path+".json", b, 0o644)
+}
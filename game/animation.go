@@ -0,0 +1,85 @@
+package game
+
+import (
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/mxpaul/meteorshooter/assets"
+)
+
+// Animator plays an animation clip frame by frame.
+type Animator struct {
+	Clip    *assets.Clip
+	frame   int
+	elapsed time.Duration
+	reverse bool // Playing backwards, ping-pong clips only
+	done    bool
+}
+
+func NewAnimator(clip *assets.Clip) *Animator {
+	return &Animator{Clip: clip}
+}
+
+// Play switches to clip, restarting it unless it is already playing.
+func (a *Animator) Play(clip *assets.Clip) {
+	if a.Clip == clip {
+		return
+	}
+	a.Clip = clip
+	a.frame = 0
+	a.elapsed = 0
+	a.reverse = false
+	a.done = false
+}
+
+func (a *Animator) Update(dt time.Duration) {
+	if a.Clip == nil || a.done {
+		return
+	}
+	a.elapsed += dt
+	for !a.done && a.elapsed >= a.Clip.Frames[a.frame].Duration {
+		a.elapsed -= a.Clip.Frames[a.frame].Duration
+		a.advance()
+	}
+}
+
+func (a *Animator) advance() {
+	last := len(a.Clip.Frames) - 1
+	switch a.Clip.Loop {
+	case assets.LoopOnce:
+		if a.frame == last {
+			a.done = true
+			return
+		}
+		a.frame++
+	case assets.LoopForward:
+		a.frame = (a.frame + 1) % (last + 1)
+	case assets.LoopPingPong:
+		if last == 0 {
+			return
+		}
+		if a.frame == last {
+			a.reverse = true
+		} else if a.frame == 0 {
+			a.reverse = false
+		}
+		if a.reverse {
+			a.frame--
+		} else {
+			a.frame++
+		}
+	}
+}
+
+// IsDone reports whether a clip which does not loop reached its end.
+func (a *Animator) IsDone() bool {
+	return a.Clip == nil || a.done
+}
+
+func (a *Animator) Frame() *ebiten.Image {
+	if a.Clip == nil {
+		return nil
+	}
+	return a.Clip.Frames[a.frame].Image
+}
@@ -0,0 +1,239 @@
+package game
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// StarLayers are parallax depths of star layers, the share of camera movement
+// each one follows, farthest first.
+var StarLayers = []float64{0.05, 0.15, 0.35}
+
+// Background counts at density 1 for a 1600x1200 screen.
+const (
+	StarsPerLayer = 150
+	NebulaCount   = 3
+	NebulaDepth   = 0.02
+	PlanetDepth   = 0.08
+	PlanetTile    = 3   // Planets repeat every this many screens, so they show up now and then
+	PlayerLean    = 0.1 // Share of player movement added to camera movement, so stars move with a still camera too
+)
+
+type Star struct {
+	Position Vector // Within the layer tile, screen pixels
+	Size     float64
+	Bright   float64
+	Twinkle  float64 // Radians per tick
+	phase    float64
+}
+
+type StarLayer struct {
+	Depth float64
+	Stars []Star
+}
+
+// Backdrop is an image drifting behind the stars, a nebula or a planet.
+type Backdrop struct {
+	Image    *ebiten.Image
+	Position Vector // Within the tile
+	Drift    Vector // Pixels per tick
+	Scale    float64
+	Depth    float64
+	Tile     Window
+	Blend    ebiten.Blend
+}
+
+// Background is a parallax starfield drawn in screen space behind the world.
+type Background struct {
+	Screen    Window
+	Scale     float64 // Screen pixels per world unit
+	Layers    []StarLayer
+	Backdrops []*Backdrop // Farthest first
+	scroll    Vector      // Accumulated camera movement
+	camera    Vector
+	player    Vector
+	started   bool
+	tick      float64
+}
+
+var starImage = func() *ebiten.Image {
+	img := ebiten.NewImage(1, 1)
+	img.Fill(color.White)
+	return img
+}()
+
+// NewBackground fills the screen with stars, nebulae and planets. Density
+// scales their amount, 0 leaves the background black.
+func NewBackground(screen Window, density, scale float64) *Background {
+	b := &Background{Screen: screen, Scale: scale}
+	if density <= 0 {
+		return b
+	}
+	r := rand.New(rand.NewSource(rand.Int63()))
+	area := float64(screen.Width*screen.Height) / (WindowWidthPixels * WindowHeightPixels)
+
+	for i := 0; i < max(1, int(NebulaCount*density)); i++ {
+		img := RenderNebula(r.Int63(), 128)
+		b.Backdrops = append(b.Backdrops, &Backdrop{
+			Image:    ebiten.NewImageFromImage(img),
+			Position: Vector{X: r.Float64() * float64(2*screen.Width), Y: r.Float64() * float64(2*screen.Height)},
+			Drift:    Vector{X: (r.Float64() - 0.5) * 0.1, Y: (r.Float64() - 0.5) * 0.1},
+			Scale:    5 + r.Float64()*3,
+			Depth:    NebulaDepth,
+			Tile:     Window{Width: 2 * screen.Width, Height: 2 * screen.Height},
+			Blend:    ebiten.BlendLighter,
+		})
+	}
+	planets := int(float64(r.Intn(3)) * density)
+	for i := 0; i < planets; i++ {
+		img := RenderPlanet(r.Int63(), 30+r.Intn(60))
+		b.Backdrops = append(b.Backdrops, &Backdrop{
+			Image:    ebiten.NewImageFromImage(img),
+			Position: Vector{X: r.Float64() * float64(PlanetTile*screen.Width), Y: r.Float64() * float64(PlanetTile*screen.Height)},
+			Scale:    1,
+			Depth:    PlanetDepth,
+			Tile:     Window{Width: PlanetTile * screen.Width, Height: PlanetTile * screen.Height},
+			Blend:    ebiten.BlendSourceOver,
+		})
+	}
+
+	for li, depth := range StarLayers {
+		layer := StarLayer{Depth: depth}
+		n := int(StarsPerLayer * density * area / float64(li+1))
+		for j := 0; j < n; j++ {
+			layer.Stars = append(layer.Stars, Star{
+				Position: Vector{X: r.Float64() * float64(screen.Width), Y: r.Float64() * float64(screen.Height)},
+				Size:     1 + float64(li)*0.6 + r.Float64(),
+				Bright:   0.4 + 0.6*r.Float64(),
+				Twinkle:  (0.5 + 2*r.Float64()) * 2 * math.Pi / float64(ebiten.TPS()) / 2,
+				phase:    r.Float64() * 2 * math.Pi,
+			})
+		}
+		b.Layers = append(b.Layers, layer)
+	}
+	return b
+}
+
+// Update follows the camera, and the player a little, wrapping world seams
+// without a jump.
+func (b *Background) Update(camera, player Vector, world Window, wrap bool) {
+	b.tick++
+	for _, s := range b.Backdrops {
+		s.Position = s.Position.Plus(s.Drift)
+	}
+	if !b.started {
+		b.camera, b.player, b.started = camera, player, true
+		return
+	}
+	delta := func(from, to Vector) Vector {
+		if wrap {
+			return world.Delta(from, to)
+		}
+		return to.Minus(from)
+	}
+	b.scroll = b.scroll.Plus(delta(b.camera, camera))
+	lean := delta(b.player, player)
+	b.scroll.X += lean.X * PlayerLean
+	b.scroll.Y += lean.Y * PlayerLean
+	b.camera, b.player = camera, player
+}
+
+func (b *Background) Draw(screen *ebiten.Image) {
+	for _, s := range b.Backdrops {
+		s.Draw(screen, b.offset(s.Depth))
+	}
+	for _, layer := range b.Layers {
+		tile := b.Screen
+		off := b.offset(layer.Depth)
+		for _, star := range layer.Stars {
+			p := tile.Wrap(star.Position.Minus(off))
+			bright := star.Bright * (0.75 + 0.25*math.Sin(star.phase+b.tick*star.Twinkle))
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Scale(star.Size, star.Size)
+			op.GeoM.Translate(p.X, p.Y)
+			op.ColorScale.ScaleWithColor(color.NRGBA{R: 200, G: 210, B: 255, A: 255})
+			op.ColorScale.ScaleAlpha(float32(bright))
+			screen.DrawImage(starImage, op)
+		}
+	}
+}
+
+func (b *Background) offset(depth float64) Vector {
+	return Vector{X: b.scroll.X * depth * b.Scale, Y: b.scroll.Y * depth * b.Scale}
+}
+
+// Draw places the backdrop within its tile, which spans beyond the screen so
+// it is only seen from time to time.
+func (s *Backdrop) Draw(screen *ebiten.Image, off Vector) {
+	w, h := s.Image.Bounds().Dx(), s.Image.Bounds().Dy()
+	size := Vector{X: float64(w) * s.Scale, Y: float64(h) * s.Scale}
+	p := s.Tile.Wrap(s.Position.Minus(off).Plus(size))
+	p = p.Minus(size)
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(s.Scale, s.Scale)
+	op.GeoM.Translate(p.X, p.Y)
+	op.Blend = s.Blend
+	op.Filter = ebiten.FilterLinear
+	screen.DrawImage(s.Image, op)
+}
+
+// RenderNebula draws a soft noisy cloud fading out towards its edges.
+func RenderNebula(seed int64, size int) *image.NRGBA {
+	r := rand.New(rand.NewSource(seed))
+	tints := []color.NRGBA{
+		{R: 120, G: 60, B: 180, A: 255},
+		{R: 50, G: 90, B: 200, A: 255},
+		{R: 40, G: 150, B: 150, A: 255},
+		{R: 170, G: 50, B: 110, A: 255},
+	}
+	tint := tints[r.Intn(len(tints))]
+	noise := newValueNoise(r.Int63())
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	half := float64(size) / 2
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			p := Vector{X: float64(x) - half, Y: float64(y) - half}
+			fade := math.Max(1-p.Magnitude()/half, 0)
+			v := noise.At(p.X/24, p.Y/24)*0.6 + noise.At(p.X/10, p.Y/10)*0.3 + noise.At(p.X/4, p.Y/4)*0.1
+			a := math.Max(v-0.3, 0) * fade * 1.2
+			img.SetNRGBA(x, y, color.NRGBA{R: tint.R, G: tint.G, B: tint.B, A: uint8(math.Min(a*255, 255))})
+		}
+	}
+	return img
+}
+
+// RenderPlanet draws a banded disc lit from the upper left.
+func RenderPlanet(seed int64, radius int) *image.NRGBA {
+	r := rand.New(rand.NewSource(seed))
+	base := color.NRGBA{R: uint8(60 + r.Intn(120)), G: uint8(60 + r.Intn(120)), B: uint8(60 + r.Intn(120)), A: 255}
+	noise := newValueNoise(r.Int63())
+	bands := 3 + r.Float64()*6
+	size := 2*radius + 2
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	half := float64(size) / 2
+	light := Vector{X: -0.6, Y: -0.5}.Normalized()
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			p := Vector{X: float64(x) + 0.5 - half, Y: float64(y) + 0.5 - half}
+			d := p.Magnitude() / float64(radius)
+			if d > 1 {
+				continue
+			}
+			z := math.Sqrt(1 - d*d)
+			lit := math.Max(p.DotPrduct(light)/float64(radius)+z*0.6, 0)
+			band := 0.85 + 0.15*math.Sin(p.Y/float64(radius)*bands+noise.At(p.X/8, p.Y/8)*2)
+			shade := math.Min(0.1+lit*band, 1.2)
+			img.SetNRGBA(x, y, color.NRGBA{
+				R: uint8(math.Min(float64(base.R)*shade, 255)),
+				G: uint8(math.Min(float64(base.G)*shade, 255)),
+				B: uint8(math.Min(float64(base.B)*shade, 255)),
+				A: uint8(math.Min((1-d)*float64(radius)*255, 255)), // Antialiased rim
+			})
+		}
+	}
+	return img
+}
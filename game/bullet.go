@@ -0,0 +1,96 @@
+package game
+
+import (
+	"image/color"
+	"math"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// SpeedCurve returns bullet speed in pixels per tick for a bullet of the given age in ticks.
+type SpeedCurve func(age int) float64
+
+type BulletSpec struct {
+	Speed    float64       // Initial speed, pixels per tick
+	Accel    float64       // Speed change per tick
+	MinSpeed float64       // Lower speed clamp
+	MaxSpeed float64       // Upper speed clamp, 0 means unlimited
+	Curve    SpeedCurve    // Overrides Speed and Accel when set
+	Turn     float64       // Angular velocity, radians per tick
+	Life     time.Duration // 0 means alive until it leaves the window
+	Split    *Pattern      // Emitted where the bullet expires
+	Color    color.Color   // Tint, nil keeps sprite colors
+	Sprite   *ebiten.Image // nil means player missle sprite
+}
+
+type Bullet struct {
+	Missle
+	Spec BulletSpec
+	age  int
+	life *Timer
+}
+
+func NewBullet(pos Vector, angle float64, spec BulletSpec) *Bullet {
+	b := &Bullet{
+		Missle: Missle{
+			Position:  pos,
+			Direction: Vector{X: math.Sin(angle), Y: math.Cos(angle)},
+			Rotation:  angle,
+			Speed:     spec.Speed,
+			Sprite:    spec.Sprite,
+		},
+		Spec: spec,
+	}
+	if spec.Life > 0 {
+		b.life = NewTimer(spec.Life)
+	}
+	return b
+}
+
+func (b *Bullet) Update(g *Game) (keep bool) {
+	b.age++
+	b.UpdateSpeed()
+	if b.Spec.Turn != 0 {
+		b.Rotation += b.Spec.Turn
+		b.Direction = Vector{X: math.Sin(b.Rotation), Y: math.Cos(b.Rotation)}
+	}
+	b.Move()
+
+	if b.life != nil {
+		b.life.Update()
+		if b.life.IsReady() {
+			if b.Spec.Split != nil {
+				g.AddEmitter(NewEmitter(*b.Spec.Split, b.Position))
+			}
+			return false
+		}
+	}
+	return b.KeepInWorld(g)
+}
+
+func (b *Bullet) UpdateSpeed() {
+	if b.Spec.Curve != nil {
+		b.Speed = b.Spec.Curve(b.age)
+		return
+	}
+	b.Speed += b.Spec.Accel
+	if b.Speed < b.Spec.MinSpeed {
+		b.Speed = b.Spec.MinSpeed
+	}
+	if b.Spec.MaxSpeed > 0 && b.Speed > b.Spec.MaxSpeed {
+		b.Speed = b.Spec.MaxSpeed
+	}
+}
+
+func (b *Bullet) Radius() float64 {
+	return float64(min(b.Sprite.Bounds().Dx(), b.Sprite.Bounds().Dy())) / 2
+}
+
+func (b Bullet) Draw(screen *ebiten.Image, view ebiten.GeoM) {
+	op := b.DrawOptions(view)
+	if b.Spec.Color != nil {
+		op.ColorScale.ScaleWithColor(b.Spec.Color)
+	}
+	screen.DrawImage(b.Sprite, op)
+}
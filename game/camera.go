@@ -0,0 +1,139 @@
+package game
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	MinCameraZoom = 0.5
+	MaxCameraZoom = 2.0
+)
+
+type Camera struct {
+	Position  Vector  // World point shown at the screen center
+	Zoom      float64 // Magnification, 1 shows ViewHeight world units top to bottom
+	Scale     float64 // Screen pixels per world unit at zoom 1
+	Smoothing float64 // Share of the distance to the target covered per tick, 1 snaps
+	DeadZone  Vector  // Half size of the screen centered area the target moves in freely, world units
+	Bounded   bool    // Keep the view inside the world
+	Screen    Window
+	FX        *CameraFX
+}
+
+func NewCamera(screen Window, pos Vector) *Camera {
+	c := &Camera{
+		Position:  pos,
+		Zoom:      1,
+		Scale:     float64(screen.Height) / ViewHeight,
+		Smoothing: 0.1,
+		Bounded:   true,
+		Screen:    screen,
+		FX:        NewCameraFX(),
+	}
+	view := c.ViewSize()
+	c.DeadZone = Vector{X: view.X / 8, Y: view.Y / 8}
+	return c
+}
+
+// PixelsPerUnit returns the current screen pixels per world unit.
+func (c *Camera) PixelsPerUnit() float64 { return c.Scale * c.Zoom }
+
+// ViewSize returns the size of the visible part of the world.
+func (c *Camera) ViewSize() Vector {
+	return Vector{X: float64(c.Screen.Width) / c.PixelsPerUnit(), Y: float64(c.Screen.Height) / c.PixelsPerUnit()}
+}
+
+func (c *Camera) Update(target Vector, world Window, wrap bool) {
+	if _, dy := ebiten.Wheel(); dy != 0 {
+		c.Zoom = min(max(c.Zoom*math.Pow(1.1, dy), MinCameraZoom), MaxCameraZoom)
+	}
+
+	d := target.Minus(c.Position)
+	if wrap {
+		d = world.Delta(c.Position, target)
+	}
+	var shift Vector
+	if d.X > c.DeadZone.X {
+		shift.X = d.X - c.DeadZone.X
+	} else if d.X < -c.DeadZone.X {
+		shift.X = d.X + c.DeadZone.X
+	}
+	if d.Y > c.DeadZone.Y {
+		shift.Y = d.Y - c.DeadZone.Y
+	} else if d.Y < -c.DeadZone.Y {
+		shift.Y = d.Y + c.DeadZone.Y
+	}
+	c.Position.X += shift.X * c.Smoothing
+	c.Position.Y += shift.Y * c.Smoothing
+
+	switch {
+	case wrap:
+		c.Position = world.Wrap(c.Position)
+	case c.Bounded:
+		c.LimitToWorld(world)
+	}
+}
+
+func (c *Camera) LimitToWorld(world Window) {
+	half := c.ViewSize()
+	half.X /= 2
+	half.Y /= 2
+	c.Position.X = clampView(c.Position.X, half.X, float64(world.Width))
+	c.Position.Y = clampView(c.Position.Y, half.Y, float64(world.Height))
+}
+
+func clampView(pos, half, size float64) float64 {
+	if 2*half >= size {
+		return size / 2
+	}
+	return min(max(pos, half), size-half)
+}
+
+// GeoM returns world to screen transformation.
+func (c *Camera) GeoM() ebiten.GeoM {
+	var m ebiten.GeoM
+	off := c.FX.Offset()
+	m.Translate(-c.Position.X-off.X, -c.Position.Y-off.Y)
+	m.Rotate(c.FX.Angle())
+	m.Scale(c.PixelsPerUnit(), c.PixelsPerUnit())
+	m.Translate(float64(c.Screen.Width)/2, float64(c.Screen.Height)/2)
+	return m
+}
+
+func (c *Camera) WorldToScreen(v Vector) Vector {
+	m := c.GeoM()
+	x, y := m.Apply(v.X, v.Y)
+	return Vector{X: x, Y: y}
+}
+
+func (c *Camera) ScreenToWorld(v Vector) Vector {
+	m := c.GeoM()
+	m.Invert()
+	x, y := m.Apply(v.X, v.Y)
+	return Vector{X: x, Y: y}
+}
+
+// Ghosts returns offsets, other than zero, at which a copy of an entity of
+// radius r is visible through the camera when the world wraps around.
+func (c *Camera) Ghosts(world Window, pos Vector, r float64) []Vector {
+	view := c.ViewSize()
+	left, top := c.Position.X-view.X/2, c.Position.Y-view.Y/2
+	right, bottom := left+view.X, top+view.Y
+	width, height := float64(world.Width), float64(world.Height)
+
+	var ghosts []Vector
+	for _, x := range []float64{-width, 0, width} {
+		for _, y := range []float64{-height, 0, height} {
+			if x == 0 && y == 0 {
+				continue
+			}
+			p := pos.Plus(Vector{X: x, Y: y})
+			if p.X+r >= left && p.X-r <= right && p.Y+r >= top && p.Y-r <= bottom {
+				ghosts = append(ghosts, Vector{X: x, Y: y})
+			}
+		}
+	}
+	return ghosts
+}
@@ -3,28 +3,30 @@ package game
 import (
 	"fmt"
 	"math"
-	"time"
+	"slices"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/colorm"
-
-	"github.com/mxpaul/meteorshooter/assets"
 )
 
 type CanonSimple struct {
 	Position      Vector
 	Rotation      float64
+	Base          float64 // Rotation of whatever carries the canon
 	ShootCooldown *Timer
+	Weapon        Weapon
+	Weapons       []Weapon // Weapons it can be armed with, the first one is the default
+	TurnSpeed     float64  // Keyboard aiming speed, radians per second
 	Sprite        *ebiten.Image
+	cursorX       int
+	cursorY       int
 }
 
 func NewSimpleCanon(
 	sprite *ebiten.Image,
 ) *CanonSimple {
-	c := &CanonSimple{
-		ShootCooldown: NewReadyTimer(time.Second / 2),
-		Sprite:        sprite,
-	}
+	c := &CanonSimple{Sprite: sprite, Weapons: slices.Clone(Weapons), TurnSpeed: CanonTurnSpeed}
+	c.Arm(c.Weapons[0])
 	return c
 }
 
@@ -32,21 +34,30 @@ func (c *CanonSimple) Update(g *Game, newPosition Vector) error {
 	c.Position = newPosition
 
 	if err := c.HandleRotation(); err != nil {
-		return fmt.Errorf("canon rotation error", err)
+		return fmt.Errorf("canon rotation error: %w", err)
 	}
+	c.HandleMouse(g)
 
 	c.ShootCooldown.Update()
-	if c.ShootCooldown.IsReady() && ebiten.IsKeyPressed(ebiten.KeySpace) {
+	trigger := ebiten.IsKeyPressed(ebiten.KeySpace) || ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft)
+	if c.ShootCooldown.IsReady() && trigger {
 		c.ShootCooldown.Reset()
-		g.AddMissle(NewMissle(c.Position, c.Rotation, c.PivotY()))
-		g.AudioContext.NewPlayerFromBytes(assets.CanonShootBytes).Play()
+		for _, angle := range c.Weapon.Angles(c.Aim()) {
+			g.AddMissle(NewMissle(c.Position, angle, c.PivotY(), PerTick(g.MissleSpeed), g.Assets.MissleSprite))
+		}
+		g.PlaySound(SoundCanonShoot, c.Position)
+		aim := c.Aim()
+		g.Camera.FX.Push(Vector{X: -math.Sin(aim), Y: math.Cos(aim)}, FireKick)
 	}
 
 	return nil
 }
 
+// CanonTurnSpeed is how fast keys turn a new canon, radians per second.
+const CanonTurnSpeed = 1.2 * math.Pi
+
 func (c *CanonSimple) HandleRotation() error {
-	speed := 1.2 * math.Pi / float64(ebiten.TPS())
+	speed := PerTick(c.TurnSpeed)
 
 	switch {
 	case ebiten.IsKeyPressed(ebiten.KeyW) && ebiten.IsKeyPressed(ebiten.KeyD):
@@ -76,21 +87,53 @@ func (c *CanonSimple) HandleRotation() error {
 	return nil
 }
 
+// HandleMouse points the canon at the cursor once it moves, so keyboard aim
+// keeps working while the mouse rests.
+func (c *CanonSimple) HandleMouse(g *Game) {
+	x, y := ebiten.CursorPosition()
+	if x == c.cursorX && y == c.cursorY {
+		return
+	}
+	c.cursorX, c.cursorY = x, y
+	c.Rotation = c.Position.AngleTo(g.CursorWorld()) - c.Base
+}
+
+func (c CanonSimple) Aim() float64 { return c.Base + c.Rotation }
+
 func (c CanonSimple) PivotX() float64 { return float64(c.Sprite.Bounds().Dx()) / 2 }
 
 func (c CanonSimple) PivotY() float64 { return float64(c.Sprite.Bounds().Dy()) * 2 / 3 }
 
-func (c CanonSimple) Draw(screen *ebiten.Image, cm colorm.ColorM) {
+func (c CanonSimple) Draw(screen *ebiten.Image, cm colorm.ColorM, view ebiten.GeoM) {
 	pivotX, pivotY := c.PivotX(), c.PivotY()
 	halfW, halfH := Halves(c.Sprite)
 
 	op := &colorm.DrawImageOptions{}
 	// Canon rotation
 	op.GeoM.Translate(-pivotX, -pivotY)
-	op.GeoM.Rotate(c.Rotation)
+	op.GeoM.Rotate(c.Aim())
 	op.GeoM.Translate(pivotX, pivotY)
 	// Canon position
 	op.GeoM.Translate(c.Position.X-halfW, c.Position.Y-halfH)
+	op.GeoM.Concat(view)
 
 	colorm.DrawImage(screen, c.Sprite, cm, op)
 }
+
+// Box is the sprite rectangle turned around the pivot like the drawn canon.
+func (c CanonSimple) Box() Box {
+	halfW, halfH := Halves(c.Sprite)
+	w, h := float64(c.Sprite.Bounds().Dx()), float64(c.Sprite.Bounds().Dy())
+	topLeft := Vector{X: c.Position.X - halfW, Y: c.Position.Y - halfH}
+	b := Box{
+		Center: topLeft.Plus(Vector{X: c.PivotX(), Y: c.PivotY()}),
+		Vertex: []Vector{
+			topLeft,
+			topLeft.Plus(Vector{X: w}),
+			topLeft.Plus(Vector{X: w, Y: h}),
+			topLeft.Plus(Vector{Y: h}),
+		}}
+	aim := c.Aim()
+	b.Rotate(Vector{X: math.Sin(aim), Y: math.Cos(aim)})
+	return b
+}
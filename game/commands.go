@@ -0,0 +1,338 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"log"
+	"math"
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var errArgs = errors.New("wrong arguments")
+
+// ConsoleBullet is what bullets of patterns started from the console are like.
+var ConsoleBullet = BulletSpec{
+	Speed: PerTick(300),
+	Life:  6 * time.Second,
+	Color: color.RGBA{R: 255, G: 80, B: 80, A: 255},
+}
+
+// ConsolePatterns are fire patterns the console starts by name.
+var ConsolePatterns = map[string]func() Pattern{
+	"radial": func() Pattern { return RadialBurst(24, ConsoleBullet) },
+	"spiral": func() Pattern {
+		p := Spiral(4, math.Pi/18, 100*time.Millisecond, ConsoleBullet)
+		p.Volleys = 60
+		return p
+	},
+	"fan": func() Pattern { return AimedFan(7, math.Pi/3, 5, 400*time.Millisecond, ConsoleBullet) },
+	"stream": func() Pattern {
+		p := RotatingStream(math.Pi/12, 50*time.Millisecond, ConsoleBullet)
+		p.Volleys = 120
+		return p
+	},
+}
+
+func onOff(args []string, current bool) (bool, error) {
+	if len(args) == 0 {
+		return !current, nil
+	}
+	switch args[0] {
+	case "on", "1", "true":
+		return true, nil
+	case "off", "0", "false":
+		return false, nil
+	}
+	return current, errArgs
+}
+
+// position parses optional world coordinates from args, the cursor position
+// when there are none. The rest of args is returned.
+func (g *Game) position(args []string) (Vector, []string) {
+	if len(args) < 2 {
+		return g.CursorWorld(), args
+	}
+	x, errX := strconv.ParseFloat(args[0], 64)
+	y, errY := strconv.ParseFloat(args[1], 64)
+	if errX != nil || errY != nil {
+		return g.CursorWorld(), args
+	}
+	return Vector{X: x, Y: y}, args[2:]
+}
+
+func (g *Game) RegisterCommands() {
+	c := g.Console
+	c.Register(&Command{
+		Name: "help",
+		Args: "[command]",
+		Help: "list commands or describe one",
+		Run: func(g *Game, args []string) (string, error) {
+			if len(args) > 0 {
+				cmd := c.Command(args[0])
+				if cmd == nil {
+					return "", fmt.Errorf("unknown command %q", args[0])
+				}
+				return fmt.Sprintf("%s %s - %s", cmd.Name, cmd.Args, cmd.Help), nil
+			}
+			var lines []string
+			for _, cmd := range c.Commands {
+				lines = append(lines, fmt.Sprintf("%-10s %s", cmd.Name, cmd.Help))
+			}
+			return strings.Join(lines, "\n"), nil
+		},
+		Complete: func(g *Game, args []string) []string {
+			var names []string
+			for _, cmd := range c.Commands {
+				names = append(names, cmd.Name)
+			}
+			return names
+		},
+	})
+	c.Register(&Command{
+		Name: "clear",
+		Help: "clear console output",
+		Run: func(g *Game, args []string) (string, error) {
+			c.output = nil
+			return "", nil
+		},
+	})
+	c.Register(&Command{
+		Name: "spawn",
+		Args: "<small|medium|large> [x y] [count]",
+		Help: "spawn meteors at a world position or the cursor",
+		Run: func(g *Game, args []string) (string, error) {
+			if len(args) == 0 {
+				return "", errArgs
+			}
+			i := slices.IndexFunc(MeteorClasses, func(mc MeteorClass) bool { return mc.Name == args[0] })
+			if i < 0 {
+				return "", fmt.Errorf("unknown meteor class %q", args[0])
+			}
+			pos, rest := g.position(args[1:])
+			count := 1
+			if len(rest) > 0 {
+				n, err := strconv.Atoi(rest[0])
+				if err != nil || n < 1 {
+					return "", errArgs
+				}
+				count = n
+			}
+			for n := range count {
+				at := pos
+				if n > 0 {
+					// Scatter a group so that its meteors do not overlap completely
+					at = at.Plus(Vector{X: (rand.Float64() - 0.5) * 400, Y: (rand.Float64() - 0.5) * 400})
+				}
+				m := g.NewRandomMeteor(at, MeteorClasses[i].Scale*g.MeteorScale)
+				g.Meteor = append(g.Meteor, m)
+			}
+			return fmt.Sprintf("spawned %d %s at %.0f,%.0f", count, args[0], pos.X, pos.Y), nil
+		},
+		Complete: func(g *Game, args []string) []string {
+			if len(args) != 1 {
+				return nil
+			}
+			var names []string
+			for _, mc := range MeteorClasses {
+				names = append(names, mc.Name)
+			}
+			return names
+		},
+	})
+	c.Register(&Command{
+		Name: "god",
+		Args: "[on|off]",
+		Help: "toggle player invincibility",
+		Run: func(g *Game, args []string) (string, error) {
+			on, err := onOff(args, g.Player.Invincible)
+			if err != nil {
+				return "", err
+			}
+			g.Player.Invincible = on
+			return fmt.Sprintf("invincible: %v", on), nil
+		},
+		Complete: func(g *Game, args []string) []string { return []string{"on", "off"} },
+	})
+	c.Register(&Command{
+		Name: "weapon",
+		Args: "[name]",
+		Help: "arm the canon with a weapon or list them",
+		Run: func(g *Game, args []string) (string, error) {
+			if len(args) == 0 {
+				var names []string
+				for _, w := range g.Player.Canon.Weapons {
+					names = append(names, w.Name)
+				}
+				return fmt.Sprintf("armed: %s, weapons: %s", g.Player.Canon.Weapon.Name, strings.Join(names, " ")), nil
+			}
+			w, ok := g.Player.Canon.WeaponByName(args[0])
+			if !ok {
+				return "", fmt.Errorf("unknown weapon %q", args[0])
+			}
+			g.Player.Canon.Arm(w)
+			return "armed: " + w.Name, nil
+		},
+		Complete: func(g *Game, args []string) []string {
+			var names []string
+			for _, w := range g.Player.Canon.Weapons {
+				names = append(names, w.Name)
+			}
+			return names
+		},
+	})
+	c.Register(&Command{
+		Name: "set",
+		Args: "<tunable> <value>",
+		Help: "change a tunable",
+		Run: func(g *Game, args []string) (string, error) {
+			if len(args) != 2 {
+				return "", errArgs
+			}
+			t := g.Tunable(args[0])
+			if t == nil {
+				return "", fmt.Errorf("unknown tunable %q", args[0])
+			}
+			if err := t.Parse(args[1]); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("%s = %s", t.Name(), t), nil
+		},
+		Complete: func(g *Game, args []string) []string {
+			if len(args) != 1 {
+				return nil
+			}
+			return g.TunableNames("")
+		},
+	})
+	c.Register(&Command{
+		Name: "get",
+		Args: "[tunable]",
+		Help: "show tunables, all of them or those starting with a prefix",
+		Run: func(g *Game, args []string) (string, error) {
+			prefix := ""
+			if len(args) > 0 {
+				prefix = args[0]
+			}
+			var lines []string
+			for _, name := range g.TunableNames(prefix) {
+				t := g.Tunable(name)
+				lines = append(lines, fmt.Sprintf("%s = %s  (%s)", t.Name(), t, t.Help()))
+			}
+			return strings.Join(lines, "\n"), nil
+		},
+		Complete: func(g *Game, args []string) []string { return g.TunableNames("") },
+	})
+	c.Register(&Command{
+		Name: "save",
+		Args: "[file]",
+		Help: "save tunables, to the tuning file by default",
+		Run: func(g *Game, args []string) (string, error) {
+			path := g.TuningFile
+			if len(args) > 0 {
+				path = args[0]
+			}
+			if path == "" {
+				return "", errors.New("no tuning file, give one")
+			}
+			if err := g.SaveTunables(path); err != nil {
+				return "", err
+			}
+			return "saved " + path, nil
+		},
+	})
+	c.Register(&Command{
+		Name: "load",
+		Args: "[file]",
+		Help: "load tunables, from the tuning file by default",
+		Run: func(g *Game, args []string) (string, error) {
+			path := g.TuningFile
+			if len(args) > 0 {
+				path = args[0]
+			}
+			if path == "" {
+				return "", errors.New("no tuning file, give one")
+			}
+			if err := g.LoadTunables(path); err != nil {
+				return "", err
+			}
+			return "loaded " + path, nil
+		},
+	})
+	c.Register(&Command{
+		Name: "timescale",
+		Args: "<scale>",
+		Help: "slow down or speed up gameplay, 1 is normal",
+		Run: func(g *Game, args []string) (string, error) {
+			if len(args) != 1 {
+				return fmt.Sprintf("time scale %g", g.TimeScale), nil
+			}
+			v, err := strconv.ParseFloat(args[0], 64)
+			if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+				return "", fmt.Errorf("scale must be a number between 0 and %d", MaxTimeScale)
+			}
+			g.SetTimeScale(v)
+			return fmt.Sprintf("time scale %g", g.TimeScale), nil
+		},
+	})
+	c.Register(&Command{
+		Name: "pattern",
+		Args: "<name> [x y]",
+		Help: "start a bullet pattern at a world position or the cursor",
+		Run: func(g *Game, args []string) (string, error) {
+			if len(args) == 0 {
+				return "", errArgs
+			}
+			pattern, ok := ConsolePatterns[args[0]]
+			if !ok {
+				return "", fmt.Errorf("unknown pattern %q", args[0])
+			}
+			pos, _ := g.position(args[1:])
+			g.AddEmitter(NewEmitter(pattern(), pos))
+			return fmt.Sprintf("%s at %.0f,%.0f", args[0], pos.X, pos.Y), nil
+		},
+		Complete: func(g *Game, args []string) []string {
+			if len(args) != 1 {
+				return nil
+			}
+			var names []string
+			for name := range ConsolePatterns {
+				names = append(names, name)
+			}
+			slices.Sort(names)
+			return names
+		},
+	})
+	c.Register(&Command{
+		Name: "dump",
+		Help: "print game state, entity details go to the log",
+		Run: func(g *Game, args []string) (string, error) {
+			p := g.Player
+			summary := []string{
+				fmt.Sprintf("player at %.0f,%.0f hit %v flight %v weapon %s invincible %v",
+					p.Position.X, p.Position.Y, p.InHit, p.Flight, p.Canon.Weapon.Name, p.Invincible),
+				fmt.Sprintf("world %dx%d wrap %v camera %.0f,%.0f zoom %.2f time scale %g",
+					g.World.Width, g.World.Height, g.Wrap, g.Camera.Position.X, g.Camera.Position.Y, g.Camera.Zoom, g.TimeScale),
+				fmt.Sprintf("meteors %d missles %d bullets %d emitters %d explosions %d",
+					len(g.Meteor), len(g.Missle), len(g.Bullet), len(g.Emitter), len(g.Explosion)),
+			}
+			for _, line := range summary {
+				log.Printf("dump: %s", line)
+			}
+			for i, m := range g.Meteor {
+				log.Printf("dump: meteor %d at %.0f,%.0f radius %.0f speed %.2f", i, m.Position.X, m.Position.Y, m.Radius(), m.Velocity)
+			}
+			for i, m := range g.Missle {
+				log.Printf("dump: missle %d at %.0f,%.0f traveled %.0f", i, m.Position.X, m.Position.Y, m.Traveled)
+			}
+			for i, e := range g.Emitter {
+				log.Printf("dump: emitter %d at %.0f,%.0f fired %d", i, e.Position.X, e.Position.Y, e.fired)
+			}
+			return strings.Join(summary, "\n"), nil
+		},
+	})
+}
@@ -0,0 +1,204 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+	"slices"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// ConsoleToggle drops the console down or rolls it up. Gameplay stops while
+// it is open.
+const ConsoleToggle = ebiten.KeyBackquote
+
+const (
+	ConsoleScrollback = 200
+	ConsoleHistory    = 50
+	ConsoleHeight     = 0.4 // Share of the window
+	consoleLine       = 16  // Debug font line height, pixels
+	consoleRepeat     = 30  // Ticks a key is held before it repeats
+)
+
+var consoleBackground = color.RGBA{R: 0, G: 0, B: 16, A: 220}
+
+// Command is a console command. Run gets arguments after the command name,
+// its output is printed to the console.
+type Command struct {
+	Name     string
+	Args     string // Argument usage, like "<class> [x y]"
+	Help     string
+	Run      func(g *Game, args []string) (string, error)
+	Complete func(g *Game, args []string) []string // Candidates for the last argument, optional
+}
+
+type Console struct {
+	Open     bool
+	Commands []*Command
+	input    []rune
+	output   []string
+	history  []string
+	browse   int // History line shown while browsing with up and down, len(history) when not
+	tick     int
+}
+
+func NewConsole() *Console {
+	return &Console{}
+}
+
+func (c *Console) Register(cmd *Command) {
+	c.Commands = append(c.Commands, cmd)
+}
+
+func (c *Console) Command(name string) *Command {
+	i := slices.IndexFunc(c.Commands, func(cmd *Command) bool { return cmd.Name == name })
+	if i < 0 {
+		return nil
+	}
+	return c.Commands[i]
+}
+
+func (c *Console) Print(format string, a ...any) {
+	for _, line := range strings.Split(fmt.Sprintf(format, a...), "\n") {
+		c.output = append(c.output, line)
+	}
+	if over := len(c.output) - ConsoleScrollback; over > 0 {
+		c.output = c.output[over:]
+	}
+}
+
+// repeated reports a key press, repeating it while the key is held.
+func repeated(key ebiten.Key) bool {
+	d := inpututil.KeyPressDuration(key)
+	return d == 1 || d >= consoleRepeat && d%3 == 0
+}
+
+func (c *Console) Update(g *Game) {
+	c.tick++
+	if inpututil.IsKeyJustPressed(ConsoleToggle) {
+		c.Open = !c.Open
+		return
+	}
+	if !c.Open {
+		return
+	}
+	for _, r := range ebiten.AppendInputChars(nil) {
+		if r != '`' {
+			c.input = append(c.input, r)
+		}
+	}
+	switch {
+	case repeated(ebiten.KeyBackspace) && len(c.input) > 0:
+		c.input = c.input[:len(c.input)-1]
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeyNumpadEnter):
+		line := strings.TrimSpace(string(c.input))
+		c.input = c.input[:0]
+		c.Execute(g, line)
+	case inpututil.IsKeyJustPressed(ebiten.KeyTab):
+		c.Complete(g)
+	case repeated(ebiten.KeyUp) && c.browse > 0:
+		c.browse--
+		c.input = []rune(c.history[c.browse])
+	case repeated(ebiten.KeyDown) && c.browse < len(c.history):
+		c.browse++
+		c.input = c.input[:0]
+		if c.browse < len(c.history) {
+			c.input = []rune(c.history[c.browse])
+		}
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		c.Open = false
+	}
+}
+
+// Execute runs a command line and remembers it in history.
+func (c *Console) Execute(g *Game, line string) {
+	if line == "" {
+		return
+	}
+	c.Print("> %s", line)
+	c.history = slices.DeleteFunc(c.history, func(h string) bool { return h == line })
+	c.history = append(c.history, line)
+	if over := len(c.history) - ConsoleHistory; over > 0 {
+		c.history = c.history[over:]
+	}
+	c.browse = len(c.history)
+
+	fields := strings.Fields(line)
+	cmd := c.Command(fields[0])
+	if cmd == nil {
+		c.Print("unknown command %q, try help", fields[0])
+		return
+	}
+	out, err := cmd.Run(g, fields[1:])
+	if out != "" {
+		c.Print("%s", out)
+	}
+	if err != nil {
+		c.Print("%s: %v", cmd.Name, err)
+		c.Print("usage: %s %s", cmd.Name, cmd.Args)
+	}
+}
+
+// Complete extends the last word of the input: a command name first, then
+// arguments the command suggests. Ambiguous words are extended as far as
+// the candidates agree and the candidates get listed.
+func (c *Console) Complete(g *Game) {
+	line := string(c.input)
+	fields := strings.Fields(line)
+	if len(fields) == 0 || strings.HasSuffix(line, " ") {
+		fields = append(fields, "")
+	}
+	word := fields[len(fields)-1]
+
+	var candidates []string
+	if len(fields) == 1 {
+		for _, cmd := range c.Commands {
+			candidates = append(candidates, cmd.Name)
+		}
+	} else if cmd := c.Command(fields[0]); cmd != nil && cmd.Complete != nil {
+		candidates = cmd.Complete(g, fields[1:])
+	}
+	candidates = slices.DeleteFunc(candidates, func(s string) bool { return !strings.HasPrefix(s, word) })
+	if len(candidates) == 0 {
+		return
+	}
+
+	common := candidates[0]
+	for _, s := range candidates[1:] {
+		for !strings.HasPrefix(s, common) {
+			common = common[:len(common)-1]
+		}
+	}
+	fields[len(fields)-1] = common
+	completed := strings.Join(fields, " ")
+	if len(candidates) == 1 {
+		completed += " "
+	} else if common == word {
+		c.Print("%s", strings.Join(candidates, "  "))
+	}
+	c.input = []rune(completed)
+}
+
+func (c *Console) Draw(screen *ebiten.Image) {
+	if !c.Open {
+		return
+	}
+	w := screen.Bounds().Dx()
+	h := int(float64(screen.Bounds().Dy()) * ConsoleHeight)
+	vector.FillRect(screen, 0, 0, float32(w), float32(h), consoleBackground, false)
+
+	prompt := "> " + string(c.input)
+	if c.tick/30%2 == 0 {
+		prompt += "_"
+	}
+	y := h - consoleLine - 4
+	ebitenutil.DebugPrintAt(screen, prompt, 4, y)
+	for i := len(c.output) - 1; i >= 0 && y > consoleLine; i-- {
+		y -= consoleLine
+		ebitenutil.DebugPrintAt(screen, c.output[i], 4, y)
+	}
+}
@@ -0,0 +1,270 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+type DebugLayer int
+
+const (
+	DebugShapes     DebugLayer = iota // Collision shapes
+	DebugVelocity                     // Where entities are heading
+	DebugGrid                         // World cells shaded by the entities in them
+	DebugStats                        // TPS, FPS and entity counts
+	DebugFrameTimes                   // Graph of recent frame times
+	debugLayerCount
+)
+
+func (l DebugLayer) String() string {
+	switch l {
+	case DebugShapes:
+		return "shapes"
+	case DebugVelocity:
+		return "velocity"
+	case DebugGrid:
+		return "grid"
+	case DebugStats:
+		return "stats"
+	case DebugFrameTimes:
+		return "frames"
+	}
+	return fmt.Sprintf("DebugLayer(%d)", int(l))
+}
+
+// DebugToggle shows or hides the overlay, DebugLayerKeys switch its layers.
+const DebugToggle = ebiten.KeyF3
+
+var DebugLayerKeys = [debugLayerCount]ebiten.Key{ebiten.KeyF4, ebiten.KeyF5, ebiten.KeyF6, ebiten.KeyF7, ebiten.KeyF8}
+
+const (
+	DebugGridCell      = 256 // World units
+	DebugVelocityTicks = 15  // Velocity arrows reach where entities will be after this many ticks
+	DebugFrameSamples  = 120
+)
+
+var (
+	debugPlayer   = color.RGBA{G: 255, A: 255}
+	debugMissle   = color.RGBA{R: 255, G: 255, A: 255}
+	debugMeteor   = color.RGBA{R: 255, G: 128, A: 255}
+	debugBullet   = color.RGBA{R: 255, B: 255, A: 255}
+	debugBounds   = color.RGBA{R: 80, G: 80, B: 80, A: 255}
+	debugVelocity = color.RGBA{R: 64, G: 160, B: 255, A: 255}
+	debugGridLine = color.RGBA{R: 40, G: 40, B: 80, A: 255}
+	debugGridFill = color.RGBA{R: 64, G: 64, B: 255, A: 255}
+	debugGraph    = color.RGBA{G: 200, A: 255}
+	debugGraphBad = color.RGBA{R: 255, A: 255}
+)
+
+// Debug is an overlay showing what the game sees.
+type Debug struct {
+	Enabled    bool
+	Layers     [debugLayerCount]bool
+	frameTimes [DebugFrameSamples]time.Duration
+	next       int
+	lastFrame  time.Time
+	lastPlayer Vector
+}
+
+func NewDebug() *Debug {
+	d := &Debug{}
+	for i := range d.Layers {
+		d.Layers[i] = true
+	}
+	return d
+}
+
+// Update runs before the player moves, so the player's last position gives
+// its velocity in arcade flight.
+func (d *Debug) Update(g *Game) {
+	d.lastPlayer = g.Player.Position
+	if inpututil.IsKeyJustPressed(DebugToggle) {
+		d.Enabled = !d.Enabled
+	}
+	if !d.Enabled {
+		return
+	}
+	for i, key := range DebugLayerKeys {
+		if inpututil.IsKeyJustPressed(key) {
+			d.Layers[i] = !d.Layers[i]
+		}
+	}
+}
+
+// Frame records the time since the previous frame was drawn.
+func (d *Debug) Frame() {
+	now := time.Now()
+	if !d.lastFrame.IsZero() {
+		d.frameTimes[d.next] = now.Sub(d.lastFrame)
+		d.next = (d.next + 1) % len(d.frameTimes)
+	}
+	d.lastFrame = now
+}
+
+// DrawWorld draws layers living in the world on the canvas.
+func (d *Debug) DrawWorld(screen *ebiten.Image, g *Game, view ebiten.GeoM) {
+	if !d.Enabled {
+		return
+	}
+	if d.Layers[DebugGrid] {
+		d.DrawGrid(screen, g, view)
+	}
+	if d.Layers[DebugShapes] {
+		d.DrawShapes(screen, g, view)
+	}
+	if d.Layers[DebugVelocity] {
+		d.DrawVelocities(screen, g, view)
+	}
+}
+
+// DrawUI draws text and graphs on the window.
+func (d *Debug) DrawUI(screen *ebiten.Image, g *Game) {
+	if !d.Enabled {
+		return
+	}
+	y := 4
+	if d.Layers[DebugStats] {
+		y = d.DrawStats(screen, g, y)
+	}
+	if d.Layers[DebugFrameTimes] {
+		d.DrawFrameTimes(screen, y)
+	}
+}
+
+func (d *Debug) DrawShapes(screen *ebiten.Image, g *Game, view ebiten.GeoM) {
+	g.Player.Box().Stroke(screen, view, debugPlayer)
+	g.Player.Canon.Box().Stroke(screen, view, debugPlayer)
+	for _, m := range g.Missle {
+		m.Box().Stroke(screen, view, debugMissle)
+	}
+	for _, m := range g.Meteor {
+		Circle(m.Position, m.Radius(), 24).Stroke(screen, view, debugBounds)
+		if m.Shape != nil {
+			m.ShapeAt(m.Position).Stroke(screen, view, debugMeteor)
+		}
+	}
+	for _, b := range g.Bullet {
+		Circle(b.Position, b.Radius(), 8).Stroke(screen, view, debugBullet)
+	}
+}
+
+func (d *Debug) DrawVelocities(screen *ebiten.Image, g *Game, view ebiten.GeoM) {
+	arrow := func(pos, v Vector) {
+		x0, y0 := view.Apply(pos.X, pos.Y)
+		x1, y1 := view.Apply(pos.X+v.X*DebugVelocityTicks, pos.Y+v.Y*DebugVelocityTicks)
+		vector.StrokeLine(screen, float32(x0), float32(y0), float32(x1), float32(y1), 1, debugVelocity, true)
+		vector.FillCircle(screen, float32(x1), float32(y1), 2, debugVelocity, true)
+	}
+	player := g.Player.Velocity
+	if g.Player.Flight == FlightArcade {
+		player = g.World.Delta(d.lastPlayer, g.Player.Position)
+	}
+	arrow(g.Player.Position, player)
+	for _, m := range g.Missle {
+		arrow(m.Position, Vector{X: m.Direction.X * m.Speed, Y: -m.Direction.Y * m.Speed})
+	}
+	for _, m := range g.Meteor {
+		arrow(m.Position, Vector{X: m.Direction.X * m.Velocity, Y: -m.Direction.Y * m.Velocity})
+	}
+	for _, b := range g.Bullet {
+		arrow(b.Position, Vector{X: b.Direction.X * b.Speed, Y: -b.Direction.Y * b.Speed})
+	}
+}
+
+// DrawGrid splits the visible world into cells, the more entities a cell
+// holds the brighter it is.
+func (d *Debug) DrawGrid(screen *ebiten.Image, g *Game, view ebiten.GeoM) {
+	cell := func(p Vector) [2]int {
+		return [2]int{int(math.Floor(p.X / DebugGridCell)), int(math.Floor(p.Y / DebugGridCell))}
+	}
+	counts := map[[2]int]int{cell(g.Player.Position): 1}
+	for _, m := range g.Missle {
+		counts[cell(m.Position)]++
+	}
+	for _, m := range g.Meteor {
+		counts[cell(m.Position)]++
+	}
+	for _, b := range g.Bullet {
+		counts[cell(b.Position)]++
+	}
+
+	size := g.Camera.ViewSize()
+	first := cell(g.Camera.Position.Minus(Vector{X: size.X / 2, Y: size.Y / 2}))
+	last := cell(g.Camera.Position.Plus(Vector{X: size.X / 2, Y: size.Y / 2}))
+	for cx := first[0]; cx <= last[0]; cx++ {
+		for cy := first[1]; cy <= last[1]; cy++ {
+			x, y := float64(cx*DebugGridCell), float64(cy*DebugGridCell)
+			b := Box{Vertex: []Vector{{x, y}, {x + DebugGridCell, y}, {x + DebugGridCell, y + DebugGridCell}, {x, y + DebugGridCell}}}
+			key := [2]int{cx, cy}
+			if g.Wrap {
+				key = cell(g.World.Wrap(Vector{X: x + 1, Y: y + 1}))
+			}
+			if n := counts[key]; n > 0 {
+				var path vector.Path
+				for i, v := range b.Vertex {
+					sx, sy := view.Apply(v.X, v.Y)
+					if i == 0 {
+						path.MoveTo(float32(sx), float32(sy))
+					} else {
+						path.LineTo(float32(sx), float32(sy))
+					}
+				}
+				path.Close()
+				op := &vector.DrawPathOptions{}
+				op.ColorScale.ScaleWithColor(debugGridFill)
+				op.ColorScale.ScaleAlpha(float32(min(0.08*float64(n), 0.5)))
+				vector.FillPath(screen, &path, nil, op)
+			}
+			b.Stroke(screen, view, debugGridLine)
+		}
+	}
+}
+
+func (d *Debug) DrawStats(screen *ebiten.Image, g *Game, y int) int {
+	lines := []string{
+		fmt.Sprintf("TPS %.1f  FPS %.1f", ebiten.ActualTPS(), ebiten.ActualFPS()),
+		fmt.Sprintf("meteors %d  missles %d  bullets %d  emitters %d  explosions %d",
+			len(g.Meteor), len(g.Missle), len(g.Bullet), len(g.Emitter), len(g.Explosion)),
+		fmt.Sprintf("player %.0f,%.0f  camera %.0f,%.0f zoom %.2f",
+			g.Player.Position.X, g.Player.Position.Y, g.Camera.Position.X, g.Camera.Position.Y, g.Camera.Zoom),
+	}
+	legend := fmt.Sprintf("%v overlay", DebugToggle)
+	for i, key := range DebugLayerKeys {
+		state := "off"
+		if d.Layers[i] {
+			state = "on"
+		}
+		legend += fmt.Sprintf("  %v %v:%s", key, DebugLayer(i), state)
+	}
+	lines = append(lines, legend)
+	for _, line := range lines {
+		ebitenutil.DebugPrintAt(screen, line, 4, y)
+		y += 16
+	}
+	return y
+}
+
+// DrawFrameTimes draws a bar per frame, red ones took longer than a tick.
+func (d *Debug) DrawFrameTimes(screen *ebiten.Image, y int) {
+	const height, pxPerMs = 60, 2
+	budget := time.Second / time.Duration(ebiten.TPS())
+	vector.FillRect(screen, 4, float32(y), DebugFrameSamples*2, height, color.RGBA{A: 160}, false)
+	for i := range d.frameTimes {
+		ft := d.frameTimes[(d.next+i)%len(d.frameTimes)]
+		h := min(float32(ft.Seconds()*1000*pxPerMs), height)
+		clr := debugGraph
+		if ft > budget+budget/10 {
+			clr = debugGraphBad
+		}
+		vector.FillRect(screen, float32(4+2*i), float32(y)+height-h, 2, h, clr, false)
+	}
+	line := float32(y) + height - float32(budget.Seconds()*1000*pxPerMs)
+	vector.StrokeLine(screen, 4, line, 4+DebugFrameSamples*2, line, 1, color.White, false)
+}
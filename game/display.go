@@ -0,0 +1,93 @@
+package game
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ViewHeight is the world height seen at zoom 1, whatever the resolution.
+// World units match pixels of the default 1600x1200 resolution.
+const ViewHeight = WindowHeightPixels
+
+type ScaleMode int
+
+const (
+	ScaleLetterbox ScaleMode = iota // Keep the aspect ratio, bars fill the rest of the window
+	ScaleStretch                    // Fill the window, distorting the picture
+	ScaleInteger                    // Whole multiples of the resolution only, for crisp pixels
+)
+
+func ParseScaleMode(s string) (ScaleMode, error) {
+	switch s {
+	case "letterbox":
+		return ScaleLetterbox, nil
+	case "stretch":
+		return ScaleStretch, nil
+	case "integer":
+		return ScaleInteger, nil
+	}
+	return ScaleLetterbox, fmt.Errorf("unknown scale mode %q", s)
+}
+
+func (m ScaleMode) String() string {
+	switch m {
+	case ScaleLetterbox:
+		return "letterbox"
+	case ScaleStretch:
+		return "stretch"
+	case ScaleInteger:
+		return "integer"
+	}
+	return fmt.Sprintf("ScaleMode(%d)", int(m))
+}
+
+// Layout follows the window size: the game is drawn at its own resolution on
+// a canvas which is then scaled onto the window.
+func (g *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
+	g.Outside = Window{Width: outsideWidth, Height: outsideHeight}
+	return outsideWidth, outsideHeight
+}
+
+// Present returns the canvas to window transformation for the scale mode.
+func (g *Game) Present() ebiten.GeoM {
+	sx := float64(g.Outside.Width) / float64(g.Window.Width)
+	sy := float64(g.Outside.Height) / float64(g.Window.Height)
+	switch g.Scale {
+	case ScaleLetterbox:
+		sx = min(sx, sy)
+		sy = sx
+	case ScaleInteger:
+		sx = max(1, math.Floor(min(sx, sy)))
+		sy = sx
+	}
+	var m ebiten.GeoM
+	m.Scale(sx, sy)
+	m.Translate(
+		math.Round((float64(g.Outside.Width)-float64(g.Window.Width)*sx)/2),
+		math.Round((float64(g.Outside.Height)-float64(g.Window.Height)*sy)/2),
+	)
+	return m
+}
+
+// UIScale sizes HUD elements drawn straight on the window, anchored to its
+// edges rather than to the canvas.
+func (g *Game) UIScale() float64 {
+	return min(float64(g.Outside.Width)/float64(g.Window.Width), float64(g.Outside.Height)/float64(g.Window.Height))
+}
+
+// CursorScreen returns mouse cursor position on the canvas.
+func (g *Game) CursorScreen() Vector {
+	x, y := ebiten.CursorPosition()
+	m := g.Present()
+	m.Invert()
+	cx, cy := m.Apply(float64(x), float64(y))
+	return Vector{X: cx, Y: cy}
+}
+
+// PerTick converts a rate per second, like a speed in world units per second,
+// to the rate per game tick.
+func PerTick(perSecond float64) float64 {
+	return perSecond / float64(ebiten.TPS())
+}
@@ -0,0 +1,71 @@
+package game
+
+import (
+	"log"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/mxpaul/meteorshooter/assets"
+)
+
+// Sprite sheets the game looks for in assets/sheets, each one optional.
+const (
+	SheetPlayer  = "player"
+	SheetEffects = "effects"
+)
+
+const ClipExplosion = "explosion"
+
+// Explosion is an effect playing once where something was destroyed.
+type Explosion struct {
+	Position Vector
+	Scale    float64
+	Anim     *Animator
+}
+
+// CheckEffects drops an explosion clip that would never finish, so explosions
+// do not pile up forever.
+func CheckEffects(a *assets.Assets) {
+	clip := a.Sheets[SheetEffects].Clip(ClipExplosion)
+	if clip != nil && clip.Loop != assets.LoopOnce {
+		log.Printf("WARNING: %s clip of %s sheet loops, explosions disabled", ClipExplosion, SheetEffects)
+		delete(a.Sheets[SheetEffects].Clips, ClipExplosion)
+	}
+}
+
+// Explode plays the explosion clip sized to cover radius, if there is one.
+func (g *Game) Explode(pos Vector, radius float64) {
+	clip := g.Assets.Sheets[SheetEffects].Clip(ClipExplosion)
+	if clip == nil || clip.Loop != assets.LoopOnce {
+		return
+	}
+	w := clip.Frames[0].Image.Bounds().Dx()
+	g.Explosion = append(g.Explosion, &Explosion{
+		Position: pos,
+		Scale:    2 * radius / float64(w),
+		Anim:     NewAnimator(clip),
+	})
+}
+
+func (g *Game) UpdateExplosions() {
+	dt := time.Second / time.Duration(ebiten.TPS())
+	for i := 0; i < len(g.Explosion); i++ {
+		e := g.Explosion[i]
+		e.Anim.Update(dt)
+		if e.Anim.IsDone() {
+			g.Explosion, i = ExcludeIndexFuckOrder(g.Explosion, i)
+		}
+	}
+}
+
+func (e *Explosion) Draw(screen *ebiten.Image, view ebiten.GeoM) {
+	frame := e.Anim.Frame()
+	halfW, halfH := Halves(frame)
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(-halfW, -halfH)
+	op.GeoM.Scale(e.Scale, e.Scale)
+	op.GeoM.Translate(e.Position.X, e.Position.Y)
+	op.GeoM.Concat(view)
+	screen.DrawImage(frame, op)
+}
@@ -1,20 +1,22 @@
 package game
 
 import (
-	"bytes"
+	"errors"
 	"fmt"
-	"image/color"
+	"io/fs"
 	"log"
 	"math"
 	"math/rand"
+	"os"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/audio"
-	"github.com/hajimehoshi/ebiten/v2/audio/wav"
-	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 
 	"github.com/mxpaul/meteorshooter/assets"
+	"github.com/mxpaul/meteorshooter/sound"
 )
 
 const WindowWidthPixels = 1600
@@ -35,29 +37,139 @@ func Halves(sprite *ebiten.Image) (w, h float64) {
 // ================================== Game =========================================
 // =================================================================================
 type Game struct {
-	Window           Window
+	Assets           *assets.Assets
+	Window           Window // Resolution the game is drawn at, pixels
+	Outside          Window // Window size, pixels
+	Scale            ScaleMode
+	canvas           *ebiten.Image
+	World            Window // Playfield size, at least the screen
+	Camera           *Camera
+	Radar            Radar
+	Background       *Background
+	Debug            *Debug
+	Console          *Console
+	Tunables         []Tunable
+	TuningFile       string // Where tunables are loaded from and saved to
+	Panel            *TuningPanel
+	MeteorSpeed      float64 // World units per second
+	MeteorScale      float64 // Size of spawned meteors relative to their sprites
+	MissleSpeed      float64 // World units per second
+	MusicVolume      float64 // Music bus volume
+	SFXVolume        float64 // Sound effects bus volume
+	TimeScale        float64 // Gameplay speed, 1 is normal
+	steps            float64 // Gameplay steps due, fractions carry over ticks
 	AudioContext     *audio.Context
-	BGPlayer         *audio.Player
+	Audio            *sound.Manager
+	Music            *sound.Jukebox
+	Layers           *sound.LayeredMusic
 	Player           Player
 	Missle           []*Missle
 	MeteorSpawnTimer *Timer
 	Meteor           []*Meteor
+	Emitter          []*Emitter
+	Bullet           []*Bullet
+	Explosion        []*Explosion
+	Wrap             bool
+	MeteorSprites    bool
+	Rocks            *RockPool // Generated meteor looks, nil with meteor sprites
+	Neon             bool
+	Mute             bool
+	Paused           bool
+	Watcher          *assets.Watcher // Mod directory watcher, nil without hot reload
+	ReloadTimer      *Timer
+	muffle           float64
 }
 
-func NewGame() *Game {
-	playerCanon := NewSimpleCanon(assets.CanonSprite)
+// WrapMeteorLimit caps meteor count in a wrapping world where meteors never fly away.
+const WrapMeteorLimit = 24
+
+type Options struct {
+	Flight FlightMode
+	Wrap   bool    // Toroidal playfield, entities leaving one edge reappear at the opposite one
+	World  Window  // Playfield size in world units, zero means the view size
+	Mute   bool    // No audio device is opened at all
+	Mods   string  // Directory overlaying assets, watched for changes to hot reload them
+	Stars  float64 // Background density, lower for slow machines, 0 leaves it black
+	Neon   bool    // Draw glowing outlines instead of sprites
+
+	MeteorSprites bool      // Meteors use asset sprites instead of generated rocks
+	Resolution    Window    // Drawing resolution, zero means the default one
+	Scale         ScaleMode // How the picture fits the window
+	Shake         float64   // Screen shake strength, 0 turns it off
+	Kick          float64   // Camera kick on firing strength, 0 turns it off
+	NoHitStop     bool      // No freeze frames on heavy impacts
+	Tuning        string    // Tunables file, loaded when it exists
+}
+
+func NewGame(a *assets.Assets, opts Options) *Game {
+	window := Window{Width: WindowWidthPixels, Height: WindowHeightPixels}
+	if opts.Resolution.Width > 0 && opts.Resolution.Height > 0 {
+		window = opts.Resolution
+	}
+	camera := NewCamera(window, Vector{})
+	view := camera.ViewSize()
+	world := opts.World
+	if float64(world.Width) < view.X || float64(world.Height) < view.Y {
+		world = Window{Width: max(world.Width, int(math.Ceil(view.X))), Height: max(world.Height, int(math.Ceil(view.Y)))}
+	}
+	center := Vector{float64(world.Width) / 2, float64(world.Height) / 2}
+	camera.Position = center
+	camera.FX.Shake = opts.Shake
+	camera.FX.Kick = opts.Kick
+	camera.FX.HitStop = !opts.NoHitStop
+
+	playerCanon := NewSimpleCanon(a.CanonSprite)
 
 	player := NewPlayer(
-		Vector{WindowWidthPixels / 2, WindowHeightPixels / 2},
-		assets.PlayerSprite,
+		center,
+		a.PlayerSprite,
 		playerCanon,
 	)
+	player.Flight = opts.Flight
+	player.Sheet = a.Sheets[SheetPlayer]
+	CheckEffects(a)
 
 	g := &Game{
-		Window:           Window{Width: WindowWidthPixels, Height: WindowHeightPixels},
+		Assets:           a,
+		Window:           window,
+		Outside:          window,
+		Scale:            opts.Scale,
+		canvas:           ebiten.NewImage(window.Width, window.Height),
+		World:            world,
+		Camera:           camera,
+		Radar:            NewRadar(),
+		Debug:            NewDebug(),
+		MeteorSpeed:      MeteorSpeed,
+		MeteorScale:      MeteorScale,
+		MissleSpeed:      MissleSpeed,
+		MusicVolume:      MusicVolume,
+		SFXVolume:        SFXVolume,
+		TimeScale:        1,
+		Background:       NewBackground(window, opts.Stars, camera.Scale),
 		Player:           player,
 		MeteorSpawnTimer: NewTimer(900*time.Millisecond + time.Millisecond*time.Duration(rand.Intn(100))),
+		Wrap:             opts.Wrap,
+		MeteorSprites:    opts.MeteorSprites,
+		Neon:             opts.Neon,
+		Mute:             opts.Mute,
+		ReloadTimer:      NewTimer(ReloadInterval),
+	}
+	if !g.MeteorSprites {
+		g.Rocks = NewRockPool(RockQueue, rand.Int63())
+	}
+	if opts.Mods != "" {
+		g.Watcher = assets.NewWatcher(os.DirFS(opts.Mods))
+	}
+	g.RegisterTunables()
+	g.TuningFile = opts.Tuning
+	g.Panel = NewTuningPanel()
+	if g.TuningFile != "" {
+		if err := g.LoadTunables(g.TuningFile); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("WARNING: tunables: %v", err)
+		}
 	}
+	g.Console = NewConsole()
+	g.RegisterCommands()
 
 	return g
 }
@@ -66,6 +178,14 @@ func (g *Game) AddMissle(m *Missle) {
 	g.Missle = append(g.Missle, m)
 }
 
+func (g *Game) AddEmitter(e *Emitter) {
+	g.Emitter = append(g.Emitter, e)
+}
+
+func (g *Game) AddBullet(b *Bullet) {
+	g.Bullet = append(g.Bullet, b)
+}
+
 func ExcludeIndexFuckOrder[T any](s []T, i int) ([]T, int) {
 	j := len(s) - 1
 	if i == j {
@@ -79,38 +199,128 @@ func ExcludeIndexFuckOrder[T any](s []T, i int) ([]T, int) {
 	return s, i
 }
 
+const (
+	SoundCanonShoot    = "canon_shoot"
+	SoundMeteorExplode = "meteor_explode"
+	SoundPlayerHit     = "player_hit"
+	SoundPickup        = "pickup"
+	SoundBlip          = "blip"
+)
+
+// AudioInit sets up sound. Audio is optional: without a working audio device
+// or with -mute the game plays through a silent sink, and missing music is
+// skipped by the jukebox. Returned errors are warnings.
 func (g *Game) AudioInit() error {
-	g.AudioContext = audio.NewContext(assets.SampleRate)
-	wavDecoded, err := wav.Decode(g.AudioContext, bytes.NewReader(assets.SpaceAmbientWav))
-	if err != nil {
-		return fmt.Errorf("background track wav decode error: %w", err)
+	g.Audio = sound.NewSilentManager()
+	var err error
+	if !g.Mute {
+		if g.AudioContext, err = NewAudioContext(); err == nil {
+			g.Audio = sound.NewManager(g.AudioContext)
+		}
 	}
-
-	loop := audio.NewInfiniteLoop(wavDecoded, wavDecoded.Length())
-	g.BGPlayer, err = g.AudioContext.NewPlayer(loop)
+	shoot := g.Audio.RegisterVariants(SoundCanonShoot, sound.BusSFX, g.Assets.CanonShootSounds, 4)
+	shoot.Pitch = sound.Range{Min: 0.92, Max: 1.08}
+	shoot.Gain = sound.Range{Min: 0.8, Max: 1}
+	explode := g.Audio.RegisterVariants(SoundMeteorExplode, sound.BusSFX, g.Assets.MeteorExplodeSounds, 6)
+	explode.Pitch = sound.Range{Min: 0.85, Max: 1.1}
+	explode.Gain = sound.Range{Min: 0.85, Max: 1}
+	g.Audio.RegisterVariants(SoundPlayerHit, sound.BusSFX, g.Assets.PlayerHitSounds, 2)
+	g.RegisterSynthSounds()
+	g.Audio.SetBusVolume(sound.BusMusic, g.MusicVolume)
+	g.Audio.SetBusVolume(sound.BusSFX, g.SFXVolume)
+	playlists, stems := AvailableMusic(g.Assets.FS)
+	g.Music = sound.NewJukebox(g.Audio, g.Assets.FS, playlists)
+	g.Layers = sound.NewLayeredMusic(g.Audio, g.Assets.FS, stems)
 	if err != nil {
-		return fmt.Errorf("background track player create error: %w", err)
+		return fmt.Errorf("audio disabled: %w", err)
+	}
+	if len(stems) == 0 {
+		return nil
+	}
+	if err = g.Layers.Start(); err != nil && !g.Audio.IsSilent() {
+		log.Printf("WARNING: adaptive music disabled: %v", err)
 	}
-	g.BGPlayer.SetVolume(0.3)
-	log.Printf("space ambient: decoded size: %d; volume :%v;", wavDecoded.Length(), g.BGPlayer.Volume())
-	g.BGPlayer.Play()
 	return nil
 }
 
+func NewAudioContext() (ctx *audio.Context, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("audio context create failed: %v", r)
+		}
+	}()
+	return audio.NewContext(assets.SampleRate), nil
+}
+
 func (g *Game) Update() (err error) {
-	if g.AudioContext == nil {
+	if g.Audio == nil {
 		if err = g.AudioInit(); err != nil {
-			return fmt.Errorf("audio context init failed: %w", err)
+			log.Printf("WARNING: %v", err)
 		}
 	}
-	if err = g.Player.Update(g); err != nil {
+	g.Console.Update(g)
+	if !g.Console.Open {
+		if inpututil.IsKeyJustPressed(ebiten.KeyM) {
+			g.Audio.ToggleMute()
+			g.Audio.Play(SoundBlip)
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyP) {
+			g.Paused = !g.Paused
+			g.Audio.Play(SoundBlip)
+		}
+		g.Debug.Update(g)
+		g.Panel.Update(g)
+	}
+	g.HotReload()
+	g.Audio.Update(time.Second / time.Duration(ebiten.TPS()))
+	g.UpdateAudioFX()
+	g.UpdateMusic()
+	if g.Paused || g.Console.Open {
+		return nil
+	}
+
+	// Time scale runs fewer or more gameplay steps per tick
+	g.steps += g.TimeScale
+	for ; g.steps >= 1; g.steps-- {
+		if err = g.Step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MaxTimeScale keeps fast forward from running so many steps a tick the game hangs.
+const MaxTimeScale = 8
+
+// SetTimeScale changes gameplay speed, dropping steps due at the old speed.
+func (g *Game) SetTimeScale(v float64) {
+	if math.IsNaN(v) {
+		return
+	}
+	g.TimeScale = min(max(v, 0), MaxTimeScale)
+	g.steps = 0
+}
+
+// Step advances gameplay by one tick.
+func (g *Game) Step() error {
+	if g.Camera.FX.IsFrozen() {
+		g.Camera.FX.Update()
+		return nil
+	}
+	g.Camera.FX.Update()
+	if err := g.Player.Update(g); err != nil {
 		return err
 	}
+	g.Camera.Update(g.Player.Position, g.World, g.Wrap)
+	g.Background.Update(g.Camera.Position, g.Player.Position, g.World, g.Wrap)
 
 	g.SpawnMeteors()
 	g.UpdateMeteors()
 	g.UpdateMissles()
+	g.UpdateEmitters()
+	g.UpdateBullets()
 	g.UpdateCollisions()
+	g.UpdateExplosions()
 	g.RemoveDistantMeteors()
 
 	return nil
@@ -126,21 +336,37 @@ func (g *Game) SpawnMeteors() {
 }
 
 func (g *Game) SpawnMeteor() {
-	sprite := assets.MeteorSprites[rand.Intn(len(assets.MeteorSprites))]
-	pos := Vector{
-		X: float64(rand.Intn(g.Window.Width)),
-		Y: (float64(sprite.Bounds().Dx()) / 2),
+	if g.Wrap && len(g.Meteor) >= WrapMeteorLimit {
+		return
+	}
+	m := g.NewRandomMeteor(Vector{X: float64(rand.Intn(g.World.Width))}, g.MeteorScale)
+	m.Position.Y = m.Radius()
+	g.Meteor = append(g.Meteor, m)
+}
+
+// NewRandomMeteor makes a meteor at pos heading roughly down.
+func (g *Game) NewRandomMeteor(pos Vector, scale float64) *Meteor {
+	var rock Rock
+	if g.MeteorSprites {
+		rock.Sprite = g.Assets.MeteorSprites[rand.Intn(len(g.Assets.MeteorSprites))]
+	} else {
+		rock = g.Rocks.Rock()
 	}
-	velocity := float64(g.Window.Height/ebiten.TPS()) / 5
+	velocity := PerTick(g.MeteorSpeed)
 	spin := (math.Pi * (rand.Float64() - 0.5) * 1.5) / float64(ebiten.TPS())
 	angle := math.Pi + (rand.Float64()-0.5)*math.Pi/7
-	m := NewMeteor(pos, angle, velocity, spin, sprite)
-	g.Meteor = append(g.Meteor, m)
+	m := NewMeteor(pos, angle, velocity, spin, rock.Sprite)
+	m.Shape = rock.Shape
+	m.Scale = scale
+	return m
 }
 
 func (g *Game) UpdateMeteors() {
 	for i := 0; i < len(g.Meteor); i++ {
 		g.Meteor[i].Update()
+		if g.Wrap {
+			g.Meteor[i].Position = g.World.Wrap(g.Meteor[i].Position)
+		}
 	}
 }
 
@@ -152,9 +378,28 @@ func (g *Game) UpdateMissles() {
 	}
 }
 
+func (g *Game) UpdateEmitters() {
+	for i := 0; i < len(g.Emitter); i++ {
+		if keep := g.Emitter[i].Update(g); !keep {
+			g.Emitter, i = ExcludeIndexFuckOrder(g.Emitter, i)
+		}
+	}
+}
+
+func (g *Game) UpdateBullets() {
+	for i := 0; i < len(g.Bullet); i++ {
+		if keep := g.Bullet[i].Update(g); !keep {
+			g.Bullet, i = ExcludeIndexFuckOrder(g.Bullet, i)
+		}
+	}
+}
+
 func (g *Game) RemoveDistantMeteors() {
+	if g.Wrap {
+		return
+	}
 	for i := 0; i < len(g.Meteor); i++ {
-		if g.Meteor[i].IsMeteorFarAway(g.Window) {
+		if g.Meteor[i].IsMeteorFarAway(g.World) {
 			g.Meteor, i = ExcludeIndexFuckOrder(g.Meteor, i)
 		}
 	}
@@ -164,46 +409,128 @@ func (g *Game) UpdateCollisions() {
 	for i := 0; i < len(g.Missle); i++ {
 		for j := 0; i > -1 && i < len(g.Missle) && j < len(g.Meteor); j++ {
 			m := g.Meteor[j]
-			if g.Missle[i].IntersectsCircle(m.Position, m.Radius()) {
+			pos := g.Nearest(g.Missle[i].Position, m.Position)
+			if g.Missle[i].IntersectsCircle(pos, m.Radius()) && m.Touches(pos, g.Missle[i].Box()) {
 				// log.Printf("HIT! Missle: %v Meteor: %v", i, j)
 				g.Missle, i = ExcludeIndexFuckOrder(g.Missle, i)
 				g.Meteor, j = ExcludeIndexFuckOrder(g.Meteor, j)
-				g.AudioContext.NewPlayerFromBytes(assets.MeteorExplodeBytes).Play()
+				g.PlaySound(SoundMeteorExplode, m.Position)
+				g.Explode(m.Position, m.Radius())
+				g.ShakeForKill(m)
 			}
 		}
 	}
 	for i := 0; i < len(g.Meteor); i++ {
 		m := g.Meteor[i]
-		if g.Player.IntersectsCircle(m.Position, m.Radius()) {
+		pos := g.Nearest(g.Player.Position, m.Position)
+		if g.Player.IntersectsCircle(pos, m.Radius()) && m.Touches(pos, g.Player.Box()) {
 			log.Printf("HIT PLAYER Meteor: %v", i)
 			g.Meteor, i = ExcludeIndexFuckOrder(g.Meteor, i)
-			g.Player.Hit(g.AudioContext)
+			g.Explode(m.Position, m.Radius())
+			g.Player.Hit(g)
+		}
+	}
+	for i := 0; i < len(g.Bullet); i++ {
+		b := g.Bullet[i]
+		if g.Player.IntersectsCircle(g.Nearest(g.Player.Position, b.Position), b.Radius()) {
+			g.Bullet, i = ExcludeIndexFuckOrder(g.Bullet, i)
+			g.Player.Hit(g)
 		}
 	}
 }
 
 func (g *Game) Draw(screen *ebiten.Image) {
-	g.Player.Draw(screen)
+	g.Debug.Frame()
+	g.canvas.Clear()
+	g.DrawWorld(g.canvas)
+	op := &ebiten.DrawImageOptions{GeoM: g.Present()}
+	if g.Scale != ScaleInteger {
+		op.Filter = ebiten.FilterLinear
+	}
+	screen.DrawImage(g.canvas, op)
+	g.DrawUI(screen)
+}
+
+// DrawWorld draws the playfield at the game resolution.
+func (g *Game) DrawWorld(screen *ebiten.Image) {
+	view := g.Camera.GeoM()
+	g.Background.Draw(screen)
+	g.DrawEntity(screen, view, g.Player)
 	for _, m := range g.Missle {
-		m.Draw(screen)
+		g.DrawEntity(screen, view, m)
 	}
 	for _, m := range g.Meteor {
-		m.Draw(screen)
+		g.DrawEntity(screen, view, m)
+	}
+	for _, b := range g.Bullet {
+		g.DrawEntity(screen, view, b)
+	}
+	for _, e := range g.Explosion {
+		g.DrawEntity(screen, view, e)
+	}
+	if g.Wrap {
+		g.DrawGhosts(screen, view)
+	}
+	g.DrawBorder(screen, view)
+	g.Radar.DrawThreats(screen, g)
+	g.Debug.DrawWorld(screen, g, view)
+}
+
+// DrawUI draws the HUD straight on the window, anchored to its edges.
+func (g *Game) DrawUI(screen *ebiten.Image) {
+	g.Radar.DrawRadar(screen, g)
+	g.Debug.DrawUI(screen, g)
+	g.Panel.Draw(screen, g)
+	if g.Paused {
+		ebitenutil.DebugPrintAt(screen, "PAUSED", g.Outside.Width/2-18, g.Outside.Height/2-8)
 	}
-	g.DrawBorder(screen)
+	g.Console.Draw(screen)
 }
 
-func (g *Game) DrawBorder(screen *ebiten.Image) {
-	borderColor := &color.RGBA{G: 255}
-	w, h := float32(g.Window.Width), float32(g.Window.Height)
-	vector.StrokeLine(screen, 0, 0, w, 0, 2.0, borderColor, false)
-	vector.StrokeLine(screen, w, 0, w, h, 2.0, borderColor, false)
-	vector.StrokeLine(screen, 0, h, w, h, 2.0, borderColor, false)
-	vector.StrokeLine(screen, 0, 0, 0, h, 2.0, borderColor, false)
+// DrawGhosts draws copies of entities visible across world seams.
+func (g *Game) DrawGhosts(screen *ebiten.Image, view ebiten.GeoM) {
+	for _, off := range g.Camera.Ghosts(g.World, g.Player.Position, g.Player.Radius()) {
+		g.DrawEntity(screen, view, g.Player.Shifted(off))
+	}
+	for _, m := range g.Missle {
+		for _, off := range g.Camera.Ghosts(g.World, m.Position, m.PivotY()) {
+			ghost := *m
+			ghost.Position = ghost.Position.Plus(off)
+			g.DrawEntity(screen, view, ghost)
+		}
+	}
+	for _, m := range g.Meteor {
+		for _, off := range g.Camera.Ghosts(g.World, m.Position, m.Radius()) {
+			ghost := *m
+			ghost.Position = ghost.Position.Plus(off)
+			g.DrawEntity(screen, view, ghost)
+		}
+	}
+	for _, b := range g.Bullet {
+		for _, off := range g.Camera.Ghosts(g.World, b.Position, b.PivotY()) {
+			ghost := *b
+			ghost.Position = ghost.Position.Plus(off)
+			g.DrawEntity(screen, view, ghost)
+		}
+	}
+}
+
+func (g *Game) DrawBorder(screen *ebiten.Image, view ebiten.GeoM) {
+	if g.Wrap {
+		return
+	}
+	border := Box{Vertex: []Vector{
+		{0, 0},
+		{float64(g.World.Width), 0},
+		{float64(g.World.Width), float64(g.World.Height)},
+		{0, float64(g.World.Height)},
+	}}
+	border.DrawBorder(screen, view)
 }
 
-func (g *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
-	return g.Window.Width, g.Window.Height
+// CursorWorld returns mouse cursor position in world coordinates.
+func (g *Game) CursorWorld() Vector {
+	return g.Camera.ScreenToWorld(g.CursorScreen())
 }
 
 // ================================ Game done ======================================
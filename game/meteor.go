@@ -6,6 +6,24 @@ import (
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
+// MeteorSpeed is in world units per second.
+const MeteorSpeed = 240
+
+// MeteorScale is the size of spawned meteors relative to their sprites.
+const MeteorScale = 0.5
+
+// MeteorClass is a meteor size the console spawns by name.
+type MeteorClass struct {
+	Name  string
+	Scale float64 // Relative to regular meteors
+}
+
+var MeteorClasses = []MeteorClass{
+	{"small", 0.6},
+	{"medium", 1},
+	{"large", 1.6},
+}
+
 type Meteor struct {
 	Position  Vector        // Where it is
 	Direction Vector        // Where go next
@@ -13,6 +31,7 @@ type Meteor struct {
 	Rotation  float64       // Current angle
 	Spin      float64       // Angular velocity
 	Sprite    *ebiten.Image // Personal look
+	Shape     []Vector      // Outline around the sprite center in sprite pixels, nil for a round meteor
 	Scale     float64
 }
 
@@ -29,7 +48,7 @@ func NewMeteor(
 		Direction: Vector{X: math.Sin(angle), Y: math.Cos(angle)},
 		Spin:      spin,
 		Sprite:    sprite,
-		Scale:     0.5,
+		Scale:     MeteorScale,
 	}
 	//log.Printf("New meteor data: velocity: %v; angle: %v; dir: %+v; spin: %v", velocity, angle, m.Direction, spin)
 	return m
@@ -48,7 +67,28 @@ func (m *Meteor) Update() {
 
 func (m *Meteor) Radius() float64 { return m.Scale * float64(m.Sprite.Bounds().Dx()) / 2 }
 
-func (m Meteor) Draw(screen *ebiten.Image) {
+// ShapeAt returns the outline placed at pos, rotated and scaled like the sprite.
+func (m *Meteor) ShapeAt(pos Vector) Box {
+	sin, cos := math.Sincos(m.Rotation)
+	b := Box{Center: pos, Vertex: make([]Vector, len(m.Shape))}
+	for i, v := range m.Shape {
+		b.Vertex[i] = Vector{
+			X: pos.X + m.Scale*(v.X*cos-v.Y*sin),
+			Y: pos.Y + m.Scale*(v.X*sin+v.Y*cos),
+		}
+	}
+	return b
+}
+
+// Touches refines a hit of the bounding circle at pos with the outline, if there is one.
+func (m *Meteor) Touches(pos Vector, b Box) bool {
+	if m.Shape == nil {
+		return true
+	}
+	return m.ShapeAt(pos).IntersectsBox(b)
+}
+
+func (m Meteor) Draw(screen *ebiten.Image, view ebiten.GeoM) {
 	pivotX, pivotY := Halves(m.Sprite)
 
 	op := &ebiten.DrawImageOptions{}
@@ -58,7 +98,8 @@ func (m Meteor) Draw(screen *ebiten.Image) {
 	op.GeoM.Translate(pivotX, pivotY)
 	op.GeoM.Scale(m.Scale, m.Scale)
 	// Position
-	op.GeoM.Translate(m.Position.X-pivotX/2, m.Position.Y-pivotY/2)
+	op.GeoM.Translate(m.Position.X-pivotX*m.Scale, m.Position.Y-pivotY*m.Scale)
+	op.GeoM.Concat(view)
 
 	screen.DrawImage(m.Sprite, op)
 }
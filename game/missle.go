@@ -3,8 +3,6 @@ package game
 import (
 	"math"
 
-	"github.com/mxpaul/meteorshooter/assets"
-
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
@@ -12,10 +10,16 @@ type Missle struct {
 	Position  Vector
 	Direction Vector
 	Rotation  float64
+	Speed     float64
+	Traveled  float64
 	Sprite    *ebiten.Image
 }
 
-func NewMissle(pos Vector, angle float64, distance float64) *Missle {
+// MissleSpeed is in world units per second.
+const MissleSpeed = 240
+
+// NewMissle launches a missle distance away from pos, speed is per tick.
+func NewMissle(pos Vector, angle float64, distance float64, speed float64, sprite *ebiten.Image) *Missle {
 	m := &Missle{
 		Position: Vector{
 			pos.X + math.Sin(angle)*distance,
@@ -26,19 +30,33 @@ func NewMissle(pos Vector, angle float64, distance float64) *Missle {
 			math.Cos(angle),
 		},
 		Rotation: angle,
+		Speed:    speed,
 
-		Sprite: assets.MissleSprite,
+		Sprite: sprite,
 	}
 	return m
 }
 
 func (m *Missle) Update(g *Game) (keep bool) {
-	speed := float64(WindowHeightPixels/ebiten.TPS()) / 5 // 1.5
+	m.Move()
 
-	m.Position.X += speed * m.Direction.X
-	m.Position.Y -= speed * m.Direction.Y
+	return m.KeepInWorld(g)
+}
 
-	return m.IsMissleInWindow(g.Window)
+func (m *Missle) Move() {
+	m.Position.X += m.Speed * m.Direction.X
+	m.Position.Y -= m.Speed * m.Direction.Y
+	m.Traveled += math.Abs(m.Speed)
+}
+
+// KeepInWorld reports if the missle is still in play. A wrapping world has no
+// edge to fly out of, so missles expire after crossing it once instead.
+func (m *Missle) KeepInWorld(g *Game) bool {
+	if g.Wrap {
+		m.Position = g.World.Wrap(m.Position)
+		return m.Traveled < float64(max(g.World.Width, g.World.Height))
+	}
+	return m.IsMissleInWindow(g.World)
 }
 
 func (m *Missle) IsMissleInWindow(window Window) bool {
@@ -69,7 +87,7 @@ func (m Missle) PivotX() float64 { return float64(m.Sprite.Bounds().Dx()) / 2 }
 
 func (m Missle) PivotY() float64 { return float64(m.Sprite.Bounds().Dy()) }
 
-func (m Missle) Draw(screen *ebiten.Image) {
+func (m Missle) DrawOptions(view ebiten.GeoM) *ebiten.DrawImageOptions {
 	pivotX, pivotY := m.PivotX(), m.PivotY()
 
 	op := &ebiten.DrawImageOptions{}
@@ -79,9 +97,12 @@ func (m Missle) Draw(screen *ebiten.Image) {
 	op.GeoM.Translate(pivotX, pivotY)
 	// Canon position
 	op.GeoM.Translate(m.Position.X-pivotX, m.Position.Y-pivotY)
+	op.GeoM.Concat(view)
+	return op
+}
 
-	screen.DrawImage(m.Sprite, op)
-	// m.Box().DrawBorder(screen)
+func (m Missle) Draw(screen *ebiten.Image, view ebiten.GeoM) {
+	screen.DrawImage(m.Sprite, m.DrawOptions(view))
 }
 
 func (m Missle) Box() Box {
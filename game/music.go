@@ -0,0 +1,106 @@
+package game
+
+import (
+	"io/fs"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/mxpaul/meteorshooter/sound"
+)
+
+const (
+	MusicMenu     = "menu"
+	MusicGameplay = "gameplay"
+	MusicBoss     = "boss"
+)
+
+// No music ships with the game, these are the paths a -mods directory puts
+// tracks at. Missing tracks are left out by AvailableMusic.
+
+// Stems of adaptive gameplay music, used instead of the gameplay playlist when they load.
+var Stems = []sound.Stem{
+	{Path: "music/stems/pads.ogg", Volume: 0.85, FadeIn: 0, Full: 0},
+	{Path: "music/stems/percussion.ogg", Volume: 1, FadeIn: 0.25, Full: 0.55},
+	{Path: "music/stems/lead.ogg", Volume: 0.85, FadeIn: 0.6, Full: 0.9},
+}
+
+var Playlists = map[string]sound.Playlist{
+	MusicMenu: {Tracks: []sound.Track{
+		{Path: "music/menu.ogg", Volume: 1, Loop: true},
+	}},
+	MusicGameplay: {Tracks: []sound.Track{
+		{Path: "music/spaceambient.wav", Volume: 1},
+		{Path: "music/gameplay_drift.ogg", Volume: 1},
+		{Path: "music/gameplay_pulse.ogg", Volume: 1},
+	}, Shuffle: true},
+	MusicBoss: {Tracks: []sound.Track{
+		{Path: "music/boss.ogg", Volume: 1, Loop: true, LoopStart: 8 * time.Second},
+	}},
+}
+
+// AvailableMusic keeps the playlist tracks and stems found in fsys, so a game
+// without music stays quiet instead of warning about every track. Stems only
+// play as a whole, one missing stem drops them all.
+func AvailableMusic(fsys fs.FS) (map[string]sound.Playlist, []sound.Stem) {
+	found := func(path string) bool {
+		_, err := fs.Stat(fsys, path)
+		return err == nil
+	}
+	playlists := make(map[string]sound.Playlist, len(Playlists))
+	for name, list := range Playlists {
+		tracks := list.Tracks[:0:0]
+		for _, t := range list.Tracks {
+			if found(t.Path) {
+				tracks = append(tracks, t)
+			}
+		}
+		playlists[name] = sound.Playlist{Tracks: tracks, Shuffle: list.Shuffle}
+	}
+	for _, stem := range Stems {
+		if !found(stem.Path) {
+			return playlists, nil
+		}
+	}
+	return playlists, Stems
+}
+
+// UpdateMusic makes music follow what is going on. Adaptive stems take care
+// of everything when they are available, otherwise playlists are switched:
+// hostile fire means a boss fight.
+func (g *Game) UpdateMusic() {
+	dt := time.Second / time.Duration(ebiten.TPS())
+	if g.Layers.IsPlaying() {
+		g.Layers.SetIntensity(g.Intensity())
+		g.Layers.Update(dt)
+	} else if len(g.Emitter) > 0 {
+		g.Music.Play(MusicBoss)
+	} else {
+		g.Music.Play(MusicGameplay)
+	}
+	g.Music.Update(dt)
+}
+
+// MeteorCrowd is the meteor count that alone drives intensity to its meteor share maximum.
+const MeteorCrowd = 12
+
+// Intensity rates how heated the game is, from 0 calm to 1 hectic.
+func (g *Game) Intensity() float64 {
+	var near int
+	view := g.Camera.ViewSize()
+	reach := max(view.X, view.Y)
+	for _, m := range g.Meteor {
+		if g.Nearest(g.Player.Position, m.Position).Minus(g.Player.Position).Magnitude() < reach {
+			near++
+		}
+	}
+	intensity := 0.5 * min(float64(near)/MeteorCrowd, 1)
+	if g.Player.InHit {
+		// Still blinking from a hit, the layers' response keeps it from cutting off sharply
+		intensity += 0.3
+	}
+	if len(g.Emitter) > 0 {
+		intensity += 0.4
+	}
+	return min(intensity, 1)
+}
@@ -0,0 +1,146 @@
+package game
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"github.com/mxpaul/meteorshooter/assets"
+)
+
+// Neon rendering draws glowing outlines of collision shapes instead of sprites.
+var (
+	NeonShip   = color.NRGBA{R: 0x40, G: 0xff, B: 0xff, A: 0xff}
+	NeonCanon  = color.NRGBA{R: 0xff, G: 0x40, B: 0xff, A: 0xff}
+	NeonMissle = color.NRGBA{R: 0xff, G: 0xff, B: 0x60, A: 0xff}
+	NeonMeteor = color.NRGBA{R: 0xff, G: 0x90, B: 0x30, A: 0xff}
+	NeonBullet = color.NRGBA{R: 0xff, G: 0x50, B: 0x80, A: 0xff}
+)
+
+// neonPasses are stroke widths and alphas drawn on top of each other, from
+// the wide faint glow to the bright core.
+var neonPasses = []struct {
+	Width float32
+	Alpha float32
+}{
+	{7, 0.12},
+	{3.5, 0.3},
+	{1.5, 1},
+}
+
+// drawable is anything drawn by either renderer.
+type drawable interface {
+	Draw(screen *ebiten.Image, view ebiten.GeoM)
+	DrawNeon(screen *ebiten.Image, view ebiten.GeoM)
+}
+
+func (g *Game) DrawEntity(screen *ebiten.Image, view ebiten.GeoM, e drawable) {
+	if g.Neon {
+		e.DrawNeon(screen, view)
+		return
+	}
+	e.Draw(screen, view)
+}
+
+// DrawNeon strokes the closed polygon b with a glow.
+func DrawNeon(screen *ebiten.Image, b Box, view ebiten.GeoM, clr color.Color) {
+	if len(b.Vertex) < 2 {
+		return
+	}
+	var path vector.Path
+	for i, v := range b.Vertex {
+		x, y := view.Apply(v.X, v.Y)
+		if i == 0 {
+			path.MoveTo(float32(x), float32(y))
+		} else {
+			path.LineTo(float32(x), float32(y))
+		}
+	}
+	path.Close()
+	for _, pass := range neonPasses {
+		so := &vector.StrokeOptions{Width: pass.Width, LineJoin: vector.LineJoinRound}
+		op := &vector.DrawPathOptions{AntiAlias: true, Blend: ebiten.BlendLighter}
+		op.ColorScale.ScaleWithColor(clr)
+		op.ColorScale.ScaleAlpha(pass.Alpha)
+		vector.StrokePath(screen, &path, so, op)
+	}
+}
+
+// Circle approximates a circle with a polygon for neon outlines.
+func Circle(c Vector, r float64, sides int) Box {
+	b := Box{Center: c, Vertex: make([]Vector, sides)}
+	for i := range b.Vertex {
+		a := 2 * math.Pi * float64(i) / float64(sides)
+		b.Vertex[i] = Vector{X: c.X + r*math.Sin(a), Y: c.Y - r*math.Cos(a)}
+	}
+	return b
+}
+
+// Hull is the ship outline inside the collision box: a nose at the front,
+// rear corners and a notch for the engine.
+func (p Player) Hull() Box {
+	b := p.Box()
+	tl, tr, br, bl := b.Vertex[0], b.Vertex[1], b.Vertex[2], b.Vertex[3]
+	mid := func(a, c Vector, t float64) Vector {
+		return Vector{X: a.X + (c.X-a.X)*t, Y: a.Y + (c.Y-a.Y)*t}
+	}
+	rear := mid(bl, br, 0.5)
+	return Box{Center: b.Center, Vertex: []Vector{
+		mid(tl, tr, 0.5),
+		br,
+		mid(rear, b.Center, 0.4),
+		bl,
+	}}
+}
+
+func (p Player) DrawNeon(screen *ebiten.Image, view ebiten.GeoM) {
+	clr := color.Color(NeonShip)
+	if p.InHit && p.translate > 0.5 {
+		clr = color.White
+	}
+	DrawNeon(screen, p.Hull(), view, clr)
+	DrawNeon(screen, p.Canon.Box(), view, NeonCanon)
+}
+
+func (m Missle) DrawNeon(screen *ebiten.Image, view ebiten.GeoM) {
+	DrawNeon(screen, m.Box(), view, NeonMissle)
+}
+
+func (m Meteor) DrawNeon(screen *ebiten.Image, view ebiten.GeoM) {
+	if m.Shape == nil {
+		DrawNeon(screen, Circle(m.Position, m.Radius(), 16), view, NeonMeteor)
+		return
+	}
+	DrawNeon(screen, m.ShapeAt(m.Position), view, NeonMeteor)
+}
+
+func (b Bullet) DrawNeon(screen *ebiten.Image, view ebiten.GeoM) {
+	clr := color.Color(NeonBullet)
+	if b.Spec.Color != nil {
+		clr = b.Spec.Color
+	}
+	DrawNeon(screen, Circle(b.Position, b.Radius(), 8), view, clr)
+}
+
+func (e *Explosion) DrawNeon(screen *ebiten.Image, view ebiten.GeoM) {
+	e.Draw(screen, view)
+}
+
+// NeonFallback stands in blank sprites for the ones which failed to load so
+// the game can still be played with neon rendering. Entities take their sizes
+// from sprites.
+func NeonFallback(a *assets.Assets) {
+	blank := func(img **ebiten.Image, w, h int) {
+		if *img == nil {
+			*img = ebiten.NewImage(w, h)
+		}
+	}
+	blank(&a.PlayerSprite, 101, 74)
+	blank(&a.CanonSprite, 17, 38)
+	blank(&a.MissleSprite, 11, 35)
+	for i := range a.MeteorSprites {
+		blank(&a.MeteorSprites[i], 215, 215)
+	}
+}
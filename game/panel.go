@@ -0,0 +1,127 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Tuning panel keys. They stay clear of flight and aiming keys, so values
+// can be changed while playing.
+const (
+	PanelToggle = ebiten.KeyF2
+	PanelUp     = ebiten.KeyHome
+	PanelDown   = ebiten.KeyEnd
+	PanelLess   = ebiten.KeyMinus
+	PanelMore   = ebiten.KeyEqual
+	PanelReset  = ebiten.KeyBackspace
+	PanelSave   = ebiten.KeyF9
+	PanelLoad   = ebiten.KeyF10
+)
+
+const (
+	PanelWidth   = 420 // Pixels
+	PanelMessage = 3 * time.Second
+)
+
+var (
+	panelBackground = color.RGBA{R: 0, G: 16, B: 24, A: 200}
+	panelSelected   = color.RGBA{R: 0, G: 80, B: 120, A: 200}
+)
+
+// TuningPanel shows tunables on screen and edits them live.
+type TuningPanel struct {
+	Open     bool
+	selected int
+	message  string
+	shown    *Timer
+}
+
+func NewTuningPanel() *TuningPanel {
+	return &TuningPanel{shown: NewReadyTimer(PanelMessage)}
+}
+
+func (p *TuningPanel) Update(g *Game) {
+	p.shown.Update()
+	if inpututil.IsKeyJustPressed(PanelToggle) {
+		p.Open = !p.Open
+	}
+	if !p.Open || len(g.Tunables) == 0 {
+		return
+	}
+	switch {
+	case repeated(PanelUp):
+		p.selected = (p.selected + len(g.Tunables) - 1) % len(g.Tunables)
+	case repeated(PanelDown):
+		p.selected = (p.selected + 1) % len(g.Tunables)
+	}
+
+	// Shift changes values ten times faster
+	steps := 1.0
+	if ebiten.IsKeyPressed(ebiten.KeyShift) {
+		steps = 10
+	}
+	t := g.Tunables[p.selected]
+	switch {
+	case repeated(PanelLess):
+		t.Nudge(-steps)
+	case repeated(PanelMore):
+		t.Nudge(steps)
+	case inpututil.IsKeyJustPressed(PanelReset):
+		t.Reset()
+	case inpututil.IsKeyJustPressed(PanelSave):
+		p.Report(g.TuningFile, "saved", g.SaveTunables)
+	case inpututil.IsKeyJustPressed(PanelLoad):
+		p.Report(g.TuningFile, "loaded", g.LoadTunables)
+	}
+}
+
+// Report runs a file operation and shows how it went.
+func (p *TuningPanel) Report(path, done string, op func(string) error) {
+	if path == "" {
+		p.Show("no tuning file, run with -tuning")
+		return
+	}
+	if err := op(path); err != nil {
+		p.Show(err.Error())
+		return
+	}
+	p.Show(done + " " + path)
+}
+
+func (p *TuningPanel) Show(message string) {
+	p.message = message
+	p.shown.Reset()
+}
+
+func (p *TuningPanel) Draw(screen *ebiten.Image, g *Game) {
+	if !p.Open {
+		return
+	}
+	lines := len(g.Tunables) + 3
+	x := screen.Bounds().Dx() - PanelWidth - 8
+	y := 8
+	vector.FillRect(screen, float32(x), float32(y), PanelWidth, float32(lines*consoleLine+8), panelBackground, false)
+	ebitenutil.DebugPrintAt(screen, "TUNING", x+4, y+4)
+	for i, t := range g.Tunables {
+		ly := y + 4 + (i+1)*consoleLine
+		if i == p.selected {
+			vector.FillRect(screen, float32(x), float32(ly), PanelWidth, consoleLine, panelSelected, false)
+		}
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("%-16s %s", t.Name(), t), x+4, ly)
+	}
+	footer := y + 4 + (len(g.Tunables)+1)*consoleLine
+	if len(g.Tunables) > 0 {
+		ebitenutil.DebugPrintAt(screen, g.Tunables[p.selected].Help(), x+4, footer)
+	}
+	hint := fmt.Sprintf("%v/%v select  -/+ change  %v reset  %v save  %v load", PanelUp, PanelDown, PanelReset, PanelSave, PanelLoad)
+	if !p.shown.IsReady() {
+		hint = p.message
+	}
+	ebitenutil.DebugPrintAt(screen, hint, x+4, footer+consoleLine)
+}
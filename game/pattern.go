@@ -0,0 +1,130 @@
+package game
+
+import (
+	"math"
+	"time"
+)
+
+// Pattern describes hostile fire declaratively. Every volley fires Count bullets
+// spread evenly over Spread around the base direction. Angles are measured
+// clockwise from up, the same way canon rotation is.
+type Pattern struct {
+	Count     int           // Bullets per volley
+	Spread    float64       // Arc covered by a volley, 2*math.Pi or more makes a ring
+	Angle     float64       // Base direction of the first volley
+	AngleStep float64       // Base direction change after each volley
+	Aimed     bool          // Base direction points at the player, Angle is added as offset
+	Volleys   int           // Volleys to fire, 0 fires forever
+	Interval  time.Duration // Pause between volleys
+	Delay     time.Duration // Pause before the first volley
+	Bullet    BulletSpec    // What every bullet of the volley looks and moves like
+	Children  []Pattern     // Started together with this pattern from the same origin
+}
+
+func RadialBurst(count int, bullet BulletSpec) Pattern {
+	return Pattern{Count: count, Spread: 2 * math.Pi, Volleys: 1, Bullet: bullet}
+}
+
+func Spiral(arms int, step float64, interval time.Duration, bullet BulletSpec) Pattern {
+	return Pattern{Count: arms, Spread: 2 * math.Pi, AngleStep: step, Interval: interval, Bullet: bullet}
+}
+
+func AimedFan(count int, spread float64, volleys int, interval time.Duration, bullet BulletSpec) Pattern {
+	return Pattern{Count: count, Spread: spread, Aimed: true, Volleys: volleys, Interval: interval, Bullet: bullet}
+}
+
+func RotatingStream(step float64, interval time.Duration, bullet BulletSpec) Pattern {
+	return Pattern{Count: 1, AngleStep: step, Interval: interval, Bullet: bullet}
+}
+
+// Angles returns bullet directions of one volley fired around base.
+func (p Pattern) Angles(base float64) []float64 {
+	if p.Count <= 0 {
+		return nil
+	}
+	angles := make([]float64, p.Count)
+	switch {
+	case p.Spread >= 2*math.Pi:
+		step := 2 * math.Pi / float64(p.Count)
+		for i := range angles {
+			angles[i] = base + float64(i)*step
+		}
+	case p.Count == 1:
+		angles[0] = base
+	default:
+		step := p.Spread / float64(p.Count-1)
+		for i := range angles {
+			angles[i] = base - p.Spread/2 + float64(i)*step
+		}
+	}
+	return angles
+}
+
+type Emitter struct {
+	Pattern  Pattern
+	Position Vector
+	Follow   *Vector // Origin tracks this position when set, e.g. an enemy
+	delay    *Timer
+	cooldown *Timer
+	fired    int
+	angle    float64
+	children []*Emitter
+}
+
+func NewEmitter(p Pattern, pos Vector) *Emitter {
+	e := &Emitter{
+		Pattern:  p,
+		Position: pos,
+		delay:    NewTimer(p.Delay),
+		cooldown: NewReadyTimer(p.Interval),
+		angle:    p.Angle,
+	}
+	for _, child := range p.Children {
+		e.children = append(e.children, NewEmitter(child, pos))
+	}
+	return e
+}
+
+func (e *Emitter) Update(g *Game) (keep bool) {
+	if e.Follow != nil {
+		e.Position = *e.Follow
+	}
+
+	for i := 0; i < len(e.children); i++ {
+		e.children[i].Position = e.Position
+		if alive := e.children[i].Update(g); !alive {
+			e.children, i = ExcludeIndexFuckOrder(e.children, i)
+		}
+	}
+
+	if !e.IsExhausted() {
+		e.delay.Update()
+		e.cooldown.Update()
+		if e.delay.IsReady() && e.cooldown.IsReady() {
+			e.cooldown.Reset()
+			e.Fire(g)
+		}
+	}
+
+	return !e.IsExhausted() || len(e.children) > 0
+}
+
+func (e *Emitter) IsExhausted() bool {
+	return e.Pattern.Volleys > 0 && e.fired >= e.Pattern.Volleys
+}
+
+func (e *Emitter) Fire(g *Game) {
+	base := e.angle
+	if e.Pattern.Aimed {
+		base += e.Position.AngleTo(g.Nearest(e.Position, g.Player.Position))
+	}
+	spec := e.Pattern.Bullet
+	if spec.Sprite == nil {
+		spec.Sprite = g.Assets.MissleSprite
+	}
+	for _, angle := range e.Pattern.Angles(base) {
+		g.AddBullet(NewBullet(e.Position, angle, spec))
+	}
+	e.angle += e.Pattern.AngleStep
+	e.fired++
+}
@@ -1,55 +1,121 @@
 package game
 
 import (
+	"cmp"
 	"fmt"
 	"math"
-
-	"github.com/mxpaul/meteorshooter/assets"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/audio"
 	"github.com/hajimehoshi/ebiten/v2/colorm"
+
+	"github.com/mxpaul/meteorshooter/assets"
 )
 
+// Clips of the player sprite sheet, each one optional.
+const (
+	ClipPlayerIdle      = "idle"
+	ClipPlayerBankLeft  = "bank_left"
+	ClipPlayerBankRight = "bank_right"
+	ClipPlayerThrust    = "thrust" // Flame drawn behind the ship while it accelerates
+)
+
+type FlightMode int
+
+const (
+	FlightArcade   FlightMode = iota // Constant speed, no momentum, clamped to window
+	FlightInertial                   // Thrust, drag and rotation of the whole ship, wraps around window edges
+)
+
+func ParseFlightMode(s string) (FlightMode, error) {
+	switch s {
+	case "arcade":
+		return FlightArcade, nil
+	case "inertial":
+		return FlightInertial, nil
+	}
+	return FlightArcade, fmt.Errorf("unknown flight mode %q", s)
+}
+
+func (m FlightMode) String() string {
+	switch m {
+	case FlightArcade:
+		return "arcade"
+	case FlightInertial:
+		return "inertial"
+	}
+	return fmt.Sprintf("FlightMode(%d)", int(m))
+}
+
 type Player struct {
-	Position  Vector
-	Sprite    *ebiten.Image
-	Speed     float64
-	Canon     *CanonSimple
-	InHit     bool
-	translate float64
-	blinkRate float64
-	blinkUp   bool
+	Position   Vector
+	Sprite     *ebiten.Image
+	Speed      float64
+	Canon      *CanonSimple
+	InHit      bool
+	Flight     FlightMode
+	Invincible bool                // Hits do no harm
+	Rotation   float64             // Ship heading, inertial flight only
+	Velocity   Vector              // Pixels per tick, inertial flight only
+	Thrust     float64             // Velocity gain per tick
+	TurnSpeed  float64             // Radians per tick
+	Drag       float64             // Velocity multiplier per tick
+	MaxSpeed   float64             // Pixels per tick
+	Sheet      *assets.SpriteSheet // Animations replacing Sprite when drawn, may be nil
+	Body       *Animator
+	Thruster   *Animator
+	banking    int // -1 turning left, 1 turning right
+	thrusting  bool
+	translate  float64
+	blinkRate  float64
+	blinkUp    bool
 }
 
+// PlayerSpeed is in world units per second.
+const PlayerSpeed = 600
+
 func NewPlayer(
 	initialPos Vector,
 	sprite *ebiten.Image,
 	canon *CanonSimple,
 ) Player {
+	speed := PerTick(PlayerSpeed)
 	p := Player{
-		Position: initialPos,
-		Sprite:   sprite,
-		Speed:    float64(WindowHeightPixels/ebiten.TPS()) / 2,
-		Canon:    canon,
+		Position:  initialPos,
+		Sprite:    sprite,
+		Speed:     speed,
+		Canon:     canon,
+		Thrust:    speed / float64(ebiten.TPS()),
+		TurnSpeed: 1.5 * math.Pi / float64(ebiten.TPS()),
+		Drag:      0.99,
+		MaxSpeed:  speed * 1.5,
 	}
 	return p
 }
 
 func (p *Player) UpdatePosition(g *Game) error {
+	if p.Flight == FlightInertial {
+		return p.UpdateInertialPosition(g)
+	}
+
 	var delta Vector
+	p.banking = 0
+	p.thrusting = false
 
 	if ebiten.IsKeyPressed(ebiten.KeyDown) {
 		delta.Y = p.Speed
 	}
 	if ebiten.IsKeyPressed(ebiten.KeyUp) {
 		delta.Y = -p.Speed
+		p.thrusting = true
 	}
 	if ebiten.IsKeyPressed(ebiten.KeyLeft) {
 		delta.X = -p.Speed
+		p.banking = -1
 	}
 	if ebiten.IsKeyPressed(ebiten.KeyRight) {
 		delta.X = p.Speed
+		p.banking = 1
 	}
 
 	// Check for diagonal movement
@@ -62,10 +128,54 @@ func (p *Player) UpdatePosition(g *Game) error {
 	p.Position.X += delta.X
 	p.Position.Y += delta.Y
 
-	p.LimitPositionToWindow(g.Window)
+	if g.Wrap {
+		p.WrapPositionToWindow(g.World)
+	} else {
+		p.LimitPositionToWindow(g.World)
+	}
+	return nil
+}
+
+func (p *Player) UpdateInertialPosition(g *Game) error {
+	p.banking = 0
+	if ebiten.IsKeyPressed(ebiten.KeyLeft) {
+		p.Rotation -= p.TurnSpeed
+		p.banking = -1
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyRight) {
+		p.Rotation += p.TurnSpeed
+		p.banking = 1
+	}
+
+	heading := Vector{X: math.Sin(p.Rotation), Y: -math.Cos(p.Rotation)}
+	p.thrusting = ebiten.IsKeyPressed(ebiten.KeyUp)
+	if p.thrusting {
+		p.Velocity.X += heading.X * p.Thrust
+		p.Velocity.Y += heading.Y * p.Thrust
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyDown) {
+		p.Velocity.X -= heading.X * p.Thrust / 2
+		p.Velocity.Y -= heading.Y * p.Thrust / 2
+	}
+
+	p.Velocity.X *= p.Drag
+	p.Velocity.Y *= p.Drag
+	if speed := p.Velocity.Magnitude(); speed > p.MaxSpeed {
+		p.Velocity.X *= p.MaxSpeed / speed
+		p.Velocity.Y *= p.MaxSpeed / speed
+	}
+
+	p.Position.X += p.Velocity.X
+	p.Position.Y += p.Velocity.Y
+
+	p.WrapPositionToWindow(g.World)
 	return nil
 }
 
+func (p *Player) WrapPositionToWindow(window Window) {
+	p.Position = window.Wrap(p.Position)
+}
+
 func (p *Player) LimitPositionToWindow(window Window) {
 	halfW, halfH := Halves(p.Sprite)
 	leftXLimit := halfW
@@ -107,30 +217,94 @@ func (p *Player) Update(g *Game) error {
 	if err := p.UpdatePosition(g); err != nil {
 		return fmt.Errorf("player update position failed: %w", err)
 	}
+	p.Canon.Base = p.Rotation
 	if err := p.Canon.Update(g, p.Position); err != nil {
 		return fmt.Errorf("player canon update failed: %w", err)
 	}
+	p.UpdateAnimation()
 	return nil
 }
 
-func (p Player) Draw(screen *ebiten.Image) {
-	halfW, halfH := Halves(p.Sprite)
+// UpdateAnimation picks banking and thruster clips from the last movement.
+func (p *Player) UpdateAnimation() {
+	if p.Sheet == nil {
+		return
+	}
+	if p.Body == nil {
+		p.Body = NewAnimator(nil)
+		p.Thruster = NewAnimator(nil)
+	}
+	body := p.Sheet.Clip(ClipPlayerIdle)
+	switch p.banking {
+	case -1:
+		body = cmp.Or(p.Sheet.Clip(ClipPlayerBankLeft), body)
+	case 1:
+		body = cmp.Or(p.Sheet.Clip(ClipPlayerBankRight), body)
+	}
+	p.Body.Play(body)
 
-	op := &colorm.DrawImageOptions{}
-	op.GeoM.Translate(p.Position.X-halfW, p.Position.Y-halfH)
+	var thrust *assets.Clip
+	if p.thrusting {
+		thrust = p.Sheet.Clip(ClipPlayerThrust)
+	}
+	p.Thruster.Play(thrust)
+
+	dt := time.Second / time.Duration(ebiten.TPS())
+	p.Body.Update(dt)
+	p.Thruster.Update(dt)
+}
 
+func (p Player) Draw(screen *ebiten.Image, view ebiten.GeoM) {
 	cm := colorm.ColorM{}
 	cm.Translate(p.translate, p.translate, p.translate, 0.0)
 
-	colorm.DrawImage(screen, p.Sprite, cm, op)
+	if p.Thruster != nil && p.Thruster.Frame() != nil {
+		// The flame hangs below the ship's tail
+		flame := p.Thruster.Frame()
+		_, halfH := Halves(p.Sprite)
+		flameHalfW, _ := Halves(flame)
+		op := &colorm.DrawImageOptions{}
+		op.GeoM.Translate(-flameHalfW, halfH)
+		op.GeoM.Rotate(p.Rotation)
+		op.GeoM.Translate(p.Position.X, p.Position.Y)
+		op.GeoM.Concat(view)
+		colorm.DrawImage(screen, flame, cm, op)
+	}
+
+	sprite := p.Sprite
+	if p.Body != nil && p.Body.Frame() != nil {
+		sprite = p.Body.Frame()
+	}
+	halfW, halfH := Halves(sprite)
+
+	op := &colorm.DrawImageOptions{}
+	op.GeoM.Translate(-halfW, -halfH)
+	op.GeoM.Rotate(p.Rotation)
+	op.GeoM.Translate(p.Position.X, p.Position.Y)
+	op.GeoM.Concat(view)
+
+	colorm.DrawImage(screen, sprite, cm, op)
+
+	p.Canon.Draw(screen, cm, view)
+}
 
-	p.Canon.Draw(screen, cm)
-	//p.Box().DrawBorder(screen)
+// Shifted returns a copy of the player, canon included, moved by offset.
+func (p Player) Shifted(offset Vector) Player {
+	canon := *p.Canon
+	canon.Position = canon.Position.Plus(offset)
+	p.Canon = &canon
+	p.Position = p.Position.Plus(offset)
+	return p
+}
+
+func (p Player) Radius() float64 {
+	halfW, halfH := Halves(p.Sprite)
+	return math.Hypot(halfW, halfH)
 }
 
 func (p Player) Box() Box {
 	halfW, halfH := Halves(p.Sprite)
-	return Box{
+	b := Box{
 		Center: p.Position,
 		Vertex: []Vector{
 			{p.Position.X - halfW, p.Position.Y - halfH},
@@ -138,16 +312,25 @@ func (p Player) Box() Box {
 			{p.Position.X + halfW, p.Position.Y + halfH},
 			{p.Position.X - halfW, p.Position.Y + halfH},
 		}}
+	if p.Rotation != 0 {
+		b.Rotate(Vector{X: math.Sin(p.Rotation), Y: math.Cos(p.Rotation)})
+	}
+	return b
 }
 
 func (p Player) IntersectsCircle(c Vector, r float64) bool {
 	return p.Box().IntersectsCircle(c, r)
 }
 
-func (p *Player) Hit(audioContext *audio.Context) {
+func (p *Player) Hit(g *Game) {
+	if p.Invincible {
+		return
+	}
 	p.InHit = true
 	p.translate = 0.0
 	p.blinkRate = 2.5 / float64(ebiten.TPS())
 	p.blinkUp = true
-	audioContext.NewPlayerFromBytes(assets.PlayerHitBytes).Play()
+	g.PlaySound(SoundPlayerHit, p.Position)
+	g.Camera.FX.AddTrauma(HitTrauma)
+	g.Camera.FX.Freeze(HitStop)
 }
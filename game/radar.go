@@ -0,0 +1,119 @@
+package game
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+type Radar struct {
+	Radius      float32 // Radar size on screen, pixels at UI scale 1
+	Margin      float32 // Gap between radar or arrows and screen edges, pixels at UI scale 1
+	Range       float64 // World distance shown at the radar rim
+	ThreatRange float64 // Off-screen meteors closer than this get an edge arrow
+	ArrowSize   float32 // Arrow size for a meteor right outside the screen, pixels
+	ArrowRadius float64 // Meteor radius drawn at ArrowSize, arrows grow with the square root of bigger ones
+}
+
+func NewRadar() Radar {
+	r := Radar{
+		Radius:      ViewHeight / 10,
+		Margin:      16,
+		Range:       ViewHeight,
+		ThreatRange: ViewHeight,
+		ArrowSize:   24,
+		ArrowRadius: 54, // A regular meteor sprite at regular scale
+	}
+	return r
+}
+
+var (
+	radarBackground = color.RGBA{R: 0, G: 24, B: 0, A: 160}
+	radarRim        = color.RGBA{G: 160, A: 255}
+	radarPlayer     = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	radarMeteor     = color.RGBA{R: 200, G: 160, B: 120, A: 255}
+	radarBullet     = color.RGBA{R: 255, G: 64, B: 64, A: 255}
+	threatArrow     = color.RGBA{R: 255, G: 48, B: 48, A: 255}
+)
+
+// DrawRadar draws a round radar in the bottom right window corner with entities
+// shown relative to the player.
+func (r Radar) DrawRadar(screen *ebiten.Image, g *Game) {
+	ui := float32(g.UIScale())
+	radius, margin := r.Radius*ui, r.Margin*ui
+	cx := float32(screen.Bounds().Dx()) - margin - radius
+	cy := float32(screen.Bounds().Dy()) - margin - radius
+	vector.FillCircle(screen, cx, cy, radius, radarBackground, true)
+	vector.StrokeCircle(screen, cx, cy, radius, 2, radarRim, true)
+
+	scale := float64(radius) / r.Range
+	blip := func(pos Vector, size float32, clr color.Color) {
+		d := g.Nearest(g.Player.Position, pos).Minus(g.Player.Position)
+		if d.Magnitude() > r.Range {
+			return
+		}
+		vector.FillCircle(screen, cx+float32(d.X*scale), cy+float32(d.Y*scale), size, clr, true)
+	}
+	for _, m := range g.Meteor {
+		blip(m.Position, max(2, float32(m.Radius()*scale)), radarMeteor)
+	}
+	for _, b := range g.Bullet {
+		blip(b.Position, 1.5, radarBullet)
+	}
+	vector.FillCircle(screen, cx, cy, 3, radarPlayer, true)
+}
+
+// DrawThreats draws arrows at screen edges pointing at incoming meteors that
+// are not visible yet. Bigger and closer meteors get bigger arrows.
+func (r Radar) DrawThreats(screen *ebiten.Image, g *Game) {
+	w, h := float64(g.Window.Width), float64(g.Window.Height)
+	center := Vector{X: w / 2, Y: h / 2}
+	margin := float64(r.Margin + r.ArrowSize)
+
+	for _, m := range g.Meteor {
+		pos := g.Nearest(g.Camera.Position, m.Position)
+		sp := g.Camera.WorldToScreen(pos)
+		sr := m.Radius() * g.Camera.PixelsPerUnit()
+		if sp.X+sr >= 0 && sp.X-sr <= w && sp.Y+sr >= 0 && sp.Y-sr <= h {
+			continue
+		}
+
+		toPlayer := g.Nearest(m.Position, g.Player.Position).Minus(m.Position)
+		distance := toPlayer.Magnitude()
+		if distance > r.ThreatRange {
+			continue
+		}
+		motion := Vector{X: m.Direction.X, Y: -m.Direction.Y}
+		if motion.DotPrduct(toPlayer) <= 0 {
+			continue
+		}
+
+		dir := sp.Minus(center)
+		t := math.Min((w/2-margin)/math.Abs(dir.X), (h/2-margin)/math.Abs(dir.Y))
+		tip := Vector{X: center.X + dir.X*t, Y: center.Y + dir.Y*t}
+
+		closeness := 1 - distance/r.ThreatRange
+		size := r.ArrowSize * float32(0.5+0.5*closeness) * float32(math.Sqrt(m.Radius()/r.ArrowRadius))
+		r.DrawArrow(screen, tip, dir.Normalized(), size, float32(0.4+0.6*closeness))
+	}
+}
+
+func (r Radar) DrawArrow(screen *ebiten.Image, tip Vector, dir Vector, size float32, alpha float32) {
+	side := dir.OrtogonalLeft()
+	bx := float32(tip.X) - float32(dir.X)*size
+	by := float32(tip.Y) - float32(dir.Y)*size
+	sx, sy := float32(side.X)*size/2, float32(side.Y)*size/2
+
+	var path vector.Path
+	path.MoveTo(float32(tip.X), float32(tip.Y))
+	path.LineTo(bx+sx, by+sy)
+	path.LineTo(bx-sx, by-sy)
+	path.Close()
+
+	op := &vector.DrawPathOptions{AntiAlias: true}
+	op.ColorScale.ScaleWithColor(threatArrow)
+	op.ColorScale.ScaleAlpha(alpha)
+	vector.FillPath(screen, &path, nil, op)
+}
@@ -0,0 +1,75 @@
+package game
+
+import (
+	"log"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ReloadInterval is how often the mod directory is checked for changed files.
+const ReloadInterval = time.Second
+
+// HotReload picks up sprites and sounds changed in the mod directory, so
+// artists see their work in game without restarting it.
+func (g *Game) HotReload() {
+	if g.Watcher == nil {
+		return
+	}
+	g.ReloadTimer.Update()
+	if !g.ReloadTimer.IsReady() {
+		return
+	}
+	g.ReloadTimer.Reset()
+
+	for _, name := range g.Watcher.Poll() {
+		g.ReloadAsset(name)
+	}
+}
+
+func (g *Game) ReloadAsset(name string) {
+	old, img, err := g.Assets.ReloadImage(name)
+	if err != nil {
+		log.Printf("WARNING: reload %s failed: %v", name, err)
+		return
+	}
+	if img != nil {
+		g.ReplaceSprite(old, img)
+		log.Printf("reloaded sprite %s", name)
+		return
+	}
+
+	ok, err := g.Assets.ReloadSounds(name)
+	if err != nil {
+		log.Printf("WARNING: reload %s: %v", name, err)
+	}
+	if ok && g.Audio != nil {
+		g.Audio.SetVariants(SoundCanonShoot, g.Assets.CanonShootSounds)
+		g.Audio.SetVariants(SoundMeteorExplode, g.Assets.MeteorExplodeSounds)
+		g.Audio.SetVariants(SoundPlayerHit, g.Assets.PlayerHitSounds)
+		log.Printf("reloaded sound %s", name)
+	}
+}
+
+// ReplaceSprite makes every live entity drawn with old use img instead.
+func (g *Game) ReplaceSprite(old, img *ebiten.Image) {
+	if old == nil {
+		return
+	}
+	swap := func(sprite **ebiten.Image) {
+		if *sprite == old {
+			*sprite = img
+		}
+	}
+	swap(&g.Player.Sprite)
+	swap(&g.Player.Canon.Sprite)
+	for _, m := range g.Missle {
+		swap(&m.Sprite)
+	}
+	for _, m := range g.Meteor {
+		swap(&m.Sprite)
+	}
+	for _, b := range g.Bullet {
+		swap(&b.Sprite)
+	}
+}
@@ -0,0 +1,178 @@
+package game
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Generated meteor radius range in sprite pixels, before Meteor.Scale.
+const (
+	RockMinRadius = 80
+	RockMaxRadius = 115
+)
+
+// Rock is a procedurally generated meteor look.
+type Rock struct {
+	Sprite *ebiten.Image
+	Shape  []Vector // Outline around the sprite center, sprite pixels
+}
+
+type crater struct {
+	Center Vector
+	Radius float64
+}
+
+// RockQueue is how many rocks are rendered ahead of spawns.
+const RockQueue = 8
+
+// RockPool renders rocks in the background, so spawning a meteor does not
+// stall a frame on rasterizing one. Every rock is handed out once.
+type RockPool struct {
+	rendered chan renderedRock
+}
+
+type renderedRock struct {
+	img   *image.NRGBA
+	shape []Vector
+}
+
+// NewRockPool starts rendering rocks from seed, keeping n of them ready.
+func NewRockPool(n int, seed int64) *RockPool {
+	p := &RockPool{rendered: make(chan renderedRock, n)}
+	go func() {
+		r := rand.New(rand.NewSource(seed))
+		for {
+			img, shape := RenderRock(r.Int63())
+			p.rendered <- renderedRock{img: img, shape: shape}
+		}
+	}()
+	return p
+}
+
+// Rock takes the next rendered rock, waiting only when spawns outrun
+// rendering. The image is made here, on the game goroutine.
+func (p *RockPool) Rock() Rock {
+	r := <-p.rendered
+	return Rock{Sprite: ebiten.NewImageFromImage(r.img), Shape: r.shape}
+}
+
+// RenderRock rasterizes a noisy polygon lit from the upper left with craters
+// punched into it. The outline is returned along with the image.
+func RenderRock(seed int64) (*image.NRGBA, []Vector) {
+	r := rand.New(rand.NewSource(seed))
+	radius := RockMinRadius + r.Float64()*(RockMaxRadius-RockMinRadius)
+
+	// Outline: jittered radii around the circle, smoothed a bit so that dents
+	// stay but spikes go
+	n := 10 + r.Intn(9)
+	radii := make([]float64, n)
+	for i := range radii {
+		radii[i] = radius * (0.55 + 0.45*r.Float64())
+	}
+	step := 2 * math.Pi / float64(n)
+	shape := make([]Vector, n)
+	for i := range shape {
+		rr := radii[i]/2 + radii[(i+n-1)%n]/4 + radii[(i+1)%n]/4
+		a := float64(i)*step + (r.Float64()-0.5)*step*0.6
+		shape[i] = Vector{X: math.Sin(a) * rr, Y: -math.Cos(a) * rr}
+	}
+
+	craters := make([]crater, 2+r.Intn(4))
+	for i := range craters {
+		a, d := r.Float64()*2*math.Pi, r.Float64()*radius*0.55
+		craters[i] = crater{
+			Center: Vector{X: math.Sin(a) * d, Y: math.Cos(a) * d},
+			Radius: radius * (0.1 + 0.18*r.Float64()),
+		}
+	}
+
+	base := color.NRGBA{
+		R: uint8(110 + r.Intn(30)),
+		G: uint8(95 + r.Intn(20)),
+		B: uint8(80 + r.Intn(20)),
+		A: 0xff,
+	}
+	noise := newValueNoise(r.Int63())
+
+	size := 2*int(math.Ceil(radius)) + 4
+	half := float64(size) / 2
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	outline := Box{Vertex: shape}
+	light := Vector{X: -0.5, Y: -0.6}.Normalized()
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			// 2x2 supersampling smooths the outline
+			coverage := 0
+			for _, s := range [4]Vector{{0.25, 0.25}, {0.75, 0.25}, {0.25, 0.75}, {0.75, 0.75}} {
+				if outline.Contains(Vector{X: float64(x) + s.X - half, Y: float64(y) + s.Y - half}) {
+					coverage++
+				}
+			}
+			if coverage == 0 {
+				continue
+			}
+			p := Vector{X: float64(x) + 0.5 - half, Y: float64(y) + 0.5 - half}
+
+			// Fake a sphere for lighting, then dent it with craters
+			d := math.Min(p.Magnitude()/radius, 1)
+			shade := 0.75 + 0.35*p.DotPrduct(light)/radius*(1-d*d*0.5)
+			for _, c := range craters {
+				off := p.Minus(c.Center)
+				cd := off.Magnitude() / c.Radius
+				switch {
+				case cd < 1:
+					// The bowl is lit on the side away from the light
+					shade += -0.35 * off.DotPrduct(light) / c.Radius
+					shade -= 0.12
+				case cd < 1.25:
+					shade += 0.1 * off.DotPrduct(light) / c.Radius
+				}
+			}
+			shade += 0.25 * (noise.At(p.X/12, p.Y/12) - 0.5)
+			shade += 0.1 * (noise.At(p.X/3, p.Y/3) - 0.5)
+			shade = math.Max(shade, 0.15)
+
+			img.SetNRGBA(x, y, color.NRGBA{
+				R: uint8(math.Min(float64(base.R)*shade, 255)),
+				G: uint8(math.Min(float64(base.G)*shade, 255)),
+				B: uint8(math.Min(float64(base.B)*shade, 255)),
+				A: uint8(coverage * 0xff / 4),
+			})
+		}
+	}
+	return img, shape
+}
+
+// valueNoise is smoothly interpolated lattice noise in [0, 1].
+type valueNoise struct {
+	seed uint64
+}
+
+func newValueNoise(seed int64) valueNoise {
+	return valueNoise{seed: uint64(seed)}
+}
+
+func (n valueNoise) lattice(x, y int) float64 {
+	h := n.seed ^ uint64(x)*0x9e3779b97f4a7c15 ^ uint64(y)*0xc2b2ae3d27d4eb4f
+	h ^= h >> 31
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 29
+	return float64(h>>11) / (1 << 53)
+}
+
+func (n valueNoise) At(x, y float64) float64 {
+	x0, y0 := math.Floor(x), math.Floor(y)
+	tx, ty := smooth(x-x0), smooth(y-y0)
+	ix, iy := int(x0), int(y0)
+	top := lerp(n.lattice(ix, iy), n.lattice(ix+1, iy), tx)
+	bottom := lerp(n.lattice(ix, iy+1), n.lattice(ix+1, iy+1), tx)
+	return lerp(top, bottom, ty)
+}
+
+func smooth(t float64) float64 { return t * t * (3 - 2*t) }
+
+func lerp(a, b, t float64) float64 { return a + (b-a)*t }
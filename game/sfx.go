@@ -0,0 +1,85 @@
+package game
+
+import (
+	"log"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/mxpaul/meteorshooter/assets"
+	"github.com/mxpaul/meteorshooter/sound"
+	"github.com/mxpaul/meteorshooter/synth"
+)
+
+// RegisterSynthSounds registers effects synthesized at start, a few variants each.
+func (g *Game) RegisterSynthSounds() {
+	r := rand.New(rand.NewSource(rand.Int63()))
+	variants := func(preset func(*rand.Rand) synth.Params, n int) [][]byte {
+		var v [][]byte
+		for range n {
+			samples, err := preset(r).Render(assets.SampleRate)
+			if err != nil {
+				log.Printf("WARNING: synth sound skipped: %v", err)
+				continue
+			}
+			v = append(v, synth.PCM16Stereo(samples))
+		}
+		return v
+	}
+	g.Audio.RegisterVariants(SoundPickup, sound.BusSFX, variants(synth.Pickup, 3), 3)
+	g.Audio.RegisterVariants(SoundBlip, sound.BusUI, variants(synth.Blip, 1), 2)
+}
+
+// SoundFalloff returns the distance from the player at which sound effects play at half loudness.
+func (g *Game) SoundFalloff() float64 { return g.Camera.ViewSize().X / 2 }
+
+// PlaySound plays an effect where it happened: panned by its position on
+// screen and quieter the further it is from the player.
+func (g *Game) PlaySound(name string, pos Vector) {
+	screen := g.Camera.WorldToScreen(g.Nearest(g.Camera.Position, pos))
+	pan := 2*screen.X/float64(g.Window.Width) - 1
+
+	distance := g.Nearest(g.Player.Position, pos).Minus(g.Player.Position).Magnitude()
+	gain := 1 / (1 + distance/g.SoundFalloff())
+
+	g.Audio.PlayAt(name, pan, gain)
+	if duck := DuckMusic[name]; duck > 0 {
+		g.Audio.FX(sound.BusMusic).Duck(duck * gain)
+	}
+}
+
+// DuckMusic lists how much big sounds pull music down for a moment.
+var DuckMusic = map[string]float64{
+	SoundMeteorExplode: 0.35,
+	SoundPlayerHit:     0.6,
+}
+
+const (
+	PausedMusicCutoff = 500   // Hz, music low-pass cutoff while paused
+	OpenMusicCutoff   = 20000 // Hz, where the muffle starts from
+	MuffleTime        = 300 * time.Millisecond
+	BossArenaReverb   = 0.35
+)
+
+// UpdateAudioFX muffles music while paused and adds reverb to effects in boss fights.
+func (g *Game) UpdateAudioFX() {
+	step := float64(time.Second/time.Duration(ebiten.TPS())) / float64(MuffleTime)
+	if g.Paused {
+		g.muffle = min(g.muffle+step, 1)
+	} else {
+		g.muffle = max(g.muffle-step, 0)
+	}
+	cutoff := 0.0
+	if g.muffle > 0 {
+		cutoff = OpenMusicCutoff * math.Pow(PausedMusicCutoff/OpenMusicCutoff, g.muffle)
+	}
+	g.Audio.FX(sound.BusMusic).SetLowPass(cutoff)
+
+	reverb := 0.0
+	if len(g.Emitter) > 0 {
+		reverb = BossArenaReverb
+	}
+	g.Audio.FX(sound.BusSFX).SetReverb(reverb)
+}
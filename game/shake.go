@@ -0,0 +1,108 @@
+package game
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// CameraFX are camera effects giving impacts weight. Each one can be turned
+// down or off for motion-sensitive players.
+type CameraFX struct {
+	Shake     float64 // Screen shake strength, 0 turns it off
+	Kick      float64 // Firing kick strength, 0 turns it off
+	HitStop   bool    // Freeze the game for a moment on heavy impacts
+	MaxOffset float64 // Shake offset at full trauma, world units
+	MaxAngle  float64 // Shake roll at full trauma, radians
+	Frequency float64 // Shake noise samples per second
+	Decay     float64 // Trauma lost per second
+	KickDecay float64 // Share of the kick kept per tick
+	trauma    float64
+	kick      Vector
+	frozen    int // Hit-stop ticks left
+	noise     valueNoise
+	time      float64
+	offset    Vector
+	angle     float64
+}
+
+func NewCameraFX() *CameraFX {
+	return &CameraFX{
+		Shake:     1,
+		Kick:      1,
+		HitStop:   true,
+		MaxOffset: 24,
+		MaxAngle:  0.03,
+		Frequency: 25,
+		Decay:     1.2,
+		KickDecay: 0.8,
+		noise:     newValueNoise(rand.Int63()),
+	}
+}
+
+// Camera effect amounts for game events.
+const (
+	HitTrauma     = 0.6
+	HitStop       = 90 * time.Millisecond
+	KillTrauma    = 0.25
+	BigKillTrauma = 0.45
+	BigKillStop   = 50 * time.Millisecond
+	BigKillRadius = 50 // Meteors bigger than this make big kills, world units
+	FireKick      = 6  // World units
+)
+
+// AddTrauma adds to the shake, trauma is capped at 1 and the shake grows
+// with its square so small hits stay subtle.
+func (fx *CameraFX) AddTrauma(t float64) {
+	fx.trauma = min(fx.trauma+t, 1)
+}
+
+// Freeze stops the game for d, the longest of overlapping freezes wins.
+func (fx *CameraFX) Freeze(d time.Duration) {
+	if !fx.HitStop {
+		return
+	}
+	fx.frozen = max(fx.frozen, int(d.Milliseconds())*ebiten.TPS()/1000)
+}
+
+func (fx *CameraFX) IsFrozen() bool { return fx.frozen > 0 }
+
+// Push kicks the camera towards direction, like a recoil.
+func (fx *CameraFX) Push(direction Vector, strength float64) {
+	fx.kick = fx.kick.Plus(Vector{X: direction.X * strength * fx.Kick, Y: direction.Y * strength * fx.Kick})
+}
+
+func (fx *CameraFX) Update() {
+	dt := 1 / float64(ebiten.TPS())
+	if fx.frozen > 0 {
+		fx.frozen--
+	}
+	fx.time += dt
+	fx.trauma = max(fx.trauma-fx.Decay*dt, 0)
+	fx.kick = Vector{X: fx.kick.X * fx.KickDecay, Y: fx.kick.Y * fx.KickDecay}
+
+	shake := fx.trauma * fx.trauma * fx.Shake
+	t := fx.time * fx.Frequency
+	fx.offset = Vector{
+		X: fx.kick.X + fx.MaxOffset*shake*(2*fx.noise.At(t, 0)-1),
+		Y: fx.kick.Y + fx.MaxOffset*shake*(2*fx.noise.At(t, 10)-1),
+	}
+	fx.angle = fx.MaxAngle * shake * (2*fx.noise.At(t, 20) - 1)
+}
+
+// Offset returns how far the view is moved off the camera position, world units.
+func (fx *CameraFX) Offset() Vector { return fx.offset }
+
+// Angle returns the roll of the view, radians.
+func (fx *CameraFX) Angle() float64 { return fx.angle }
+
+// ShakeForKill shakes the camera for a destroyed meteor, harder for big ones.
+func (g *Game) ShakeForKill(m *Meteor) {
+	if m.Radius() < BigKillRadius {
+		g.Camera.FX.AddTrauma(KillTrauma)
+		return
+	}
+	g.Camera.FX.AddTrauma(BigKillTrauma)
+	g.Camera.FX.Freeze(BigKillStop)
+}
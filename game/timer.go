@@ -37,3 +37,12 @@ func (t *Timer) IsReady() bool {
 func (t *Timer) Reset() {
 	t.currentTicks = 0
 }
+
+func (t *Timer) Duration() time.Duration {
+	return time.Duration(t.targetTicks) * time.Second / time.Duration(ebiten.TPS())
+}
+
+// SetDuration changes the time to wait, a timer already past it is ready.
+func (t *Timer) SetDuration(d time.Duration) {
+	t.targetTicks = int(d.Milliseconds()) * ebiten.TPS() / 1000
+}
@@ -0,0 +1,272 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/mxpaul/meteorshooter/sound"
+)
+
+// Tunable is a gameplay value adjustable while playing, from the console,
+// the tuning panel or a tuning file.
+type Tunable interface {
+	Name() string // Dotted, like canon.cooldown
+	Help() string
+	String() string
+	Parse(s string) error
+	Nudge(steps float64) // Change by steps of the value's step, booleans flip
+	Value() any          // What the tuning file stores
+	Reset()              // Back to the value at registration
+}
+
+// Value is a typed tunable.
+type Value[T float64 | int | bool | time.Duration] struct {
+	name, help string
+	Step       T
+	Min, Max   float64 // Limits of numbers, set by Limit
+	limited    bool
+	get        func() T
+	set        func(T)
+	initial    T
+}
+
+// NewVar makes a tunable of a variable.
+func NewVar[T float64 | int | bool | time.Duration](name, help string, p *T, step T) *Value[T] {
+	return NewFunc(name, help, func() T { return *p }, func(v T) { *p = v }, step)
+}
+
+// NewFunc makes a tunable of whatever get and set reach, for values needing
+// more than an assignment when they change.
+func NewFunc[T float64 | int | bool | time.Duration](name, help string, get func() T, set func(T), step T) *Value[T] {
+	return &Value[T]{name: name, help: help, Step: step, get: get, set: set, initial: get()}
+}
+
+// Limit keeps numbers within min and max.
+func (v *Value[T]) Limit(min, max float64) *Value[T] {
+	v.Min, v.Max, v.limited = min, max, true
+	return v
+}
+
+func (v *Value[T]) Name() string { return v.name }
+func (v *Value[T]) Help() string { return v.help }
+func (v *Value[T]) Get() T       { return v.get() }
+func (v *Value[T]) Reset()       { v.Set(v.initial) }
+
+func (v *Value[T]) Set(x T) {
+	if f, ok := toFloat(x); ok && v.limited {
+		x = fromFloat[T](min(max(f, v.Min), v.Max))
+	}
+	v.set(x)
+}
+
+func (v *Value[T]) String() string {
+	switch x := any(v.get()).(type) {
+	case float64:
+		return strconv.FormatFloat(x, 'g', 4, 64)
+	case int:
+		return strconv.Itoa(x)
+	case bool:
+		return strconv.FormatBool(x)
+	case time.Duration:
+		return x.String()
+	}
+	return fmt.Sprint(v.get())
+}
+
+func (v *Value[T]) Parse(s string) error {
+	var x any
+	var err error
+	switch any(v.initial).(type) {
+	case float64:
+		x, err = strconv.ParseFloat(s, 64)
+	case int:
+		x, err = strconv.Atoi(s)
+	case bool:
+		x, err = strconv.ParseBool(s)
+	case time.Duration:
+		x, err = time.ParseDuration(s)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", v.name, err)
+	}
+	if f, ok := x.(float64); ok && (math.IsNaN(f) || math.IsInf(f, 0)) {
+		return fmt.Errorf("%s: %q is not a finite number", v.name, s)
+	}
+	v.Set(x.(T))
+	return nil
+}
+
+func (v *Value[T]) Nudge(steps float64) {
+	cur := v.get()
+	if b, ok := any(cur).(bool); ok {
+		v.Set(any(!b).(T))
+		return
+	}
+	f, _ := toFloat(cur)
+	step, _ := toFloat(v.Step)
+	v.Set(fromFloat[T](f + steps*step))
+}
+
+func (v *Value[T]) Value() any {
+	if d, ok := any(v.get()).(time.Duration); ok {
+		return d.String()
+	}
+	return v.get()
+}
+
+func toFloat(x any) (float64, bool) {
+	switch x := x.(type) {
+	case float64:
+		return x, true
+	case int:
+		return float64(x), true
+	case time.Duration:
+		return float64(x), true
+	}
+	return 0, false
+}
+
+func fromFloat[T float64 | int | bool | time.Duration](f float64) T {
+	var x T
+	switch any(x).(type) {
+	case float64:
+		return any(f).(T)
+	case int:
+		return any(int(math.Round(f))).(T)
+	case time.Duration:
+		return any(time.Duration(f)).(T)
+	}
+	return x
+}
+
+func (g *Game) RegisterTunable(t Tunable) {
+	g.Tunables = append(g.Tunables, t)
+}
+
+func (g *Game) Tunable(name string) Tunable {
+	i := slices.IndexFunc(g.Tunables, func(t Tunable) bool { return t.Name() == name })
+	if i < 0 {
+		return nil
+	}
+	return g.Tunables[i]
+}
+
+// TunableNames returns names of tunables starting with prefix.
+func (g *Game) TunableNames(prefix string) []string {
+	var names []string
+	for _, t := range g.Tunables {
+		if strings.HasPrefix(t.Name(), prefix) {
+			names = append(names, t.Name())
+		}
+	}
+	return names
+}
+
+// Bus volumes of a new game. Music plays under effects, track and stem
+// volumes only balance it within the bus.
+const (
+	MusicVolume = 0.3
+	SFXVolume   = 1.0
+)
+
+func (g *Game) RegisterTunables() {
+	canon := g.Player.Canon
+	g.RegisterTunable(NewFunc("canon.cooldown", "time between shots of the default weapon",
+		func() time.Duration { return canon.Weapons[0].Cooldown },
+		func(d time.Duration) {
+			canon.Weapons[0].Cooldown = d
+			if canon.Weapon.Name == canon.Weapons[0].Name {
+				canon.Weapon.Cooldown = d
+				canon.ShootCooldown.SetDuration(d)
+			}
+		},
+		50*time.Millisecond).Limit(float64(time.Second/60), float64(5*time.Second)))
+	g.RegisterTunable(NewVar("canon.turn", "keyboard aiming speed, radians per second", &canon.TurnSpeed, 0.1).Limit(0.1, 20))
+	g.RegisterTunable(NewVar("missle.speed", "world units per second", &g.MissleSpeed, 10).Limit(10, 3000))
+	g.RegisterTunable(NewVar("meteor.scale", "size of spawned meteors relative to their sprites", &g.MeteorScale, 0.05).Limit(0.1, 3))
+	g.RegisterTunable(NewVar("meteor.speed", "world units per second of new meteors", &g.MeteorSpeed, 10).Limit(0, 3000))
+	g.RegisterTunable(NewFunc("meteor.spawn", "time between meteors",
+		g.MeteorSpawnTimer.Duration, g.MeteorSpawnTimer.SetDuration,
+		50*time.Millisecond).Limit(float64(time.Second/60), float64(10*time.Second)))
+	g.RegisterTunable(NewFunc("player.speed", "world units per second in arcade flight",
+		func() float64 { return g.Player.Speed * float64(ebiten.TPS()) },
+		func(v float64) { g.Player.Speed = PerTick(v) },
+		10).Limit(0, 3000))
+	g.RegisterTunable(NewFunc("time.scale", "gameplay speed, 1 is normal",
+		func() float64 { return g.TimeScale }, g.SetTimeScale, 0.1).Limit(0, MaxTimeScale))
+	g.RegisterTunable(NewVar("camera.shake", "screen shake strength, 0 turns it off", &g.Camera.FX.Shake, 0.1).Limit(0, 3))
+	g.RegisterTunable(NewVar("camera.hitstop", "freeze frames on heavy impacts", &g.Camera.FX.HitStop, false))
+	g.RegisterTunable(NewFunc("music.volume", "music bus volume",
+		func() float64 { return g.MusicVolume },
+		func(v float64) {
+			g.MusicVolume = v
+			if g.Audio != nil {
+				g.Audio.SetBusVolume(sound.BusMusic, v)
+			}
+		},
+		0.05).Limit(0, 1))
+	g.RegisterTunable(NewFunc("sfx.volume", "sound effects bus volume",
+		func() float64 { return g.SFXVolume },
+		func(v float64) {
+			g.SFXVolume = v
+			if g.Audio != nil {
+				g.Audio.SetBusVolume(sound.BusSFX, v)
+			}
+		},
+		0.05).Limit(0, 1))
+}
+
+// LoadTunables sets tunables from a JSON object of names and values.
+// Durations are strings like "500ms". Unknown names and bad values are
+// reported, the rest is still applied.
+func (g *Game) LoadTunables(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var values map[string]json.RawMessage
+	if err = json.Unmarshal(b, &values); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	var errs []error
+	for name, raw := range values {
+		t := g.Tunable(name)
+		if t == nil {
+			errs = append(errs, fmt.Errorf("unknown tunable %s", name))
+			continue
+		}
+		var s string
+		if json.Unmarshal(raw, &s) != nil {
+			s = string(raw)
+		}
+		if err = t.Parse(s); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err = errors.Join(errs...); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// SaveTunables writes every tunable to path in the format LoadTunables reads.
+func (g *Game) SaveTunables(path string) error {
+	values := map[string]any{}
+	for _, t := range g.Tunables {
+		values[t.Name()] = t.Value()
+	}
+	b, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
@@ -0,0 +1,130 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type tuned struct {
+	speed    float64
+	count    int
+	enabled  bool
+	cooldown time.Duration
+}
+
+func newTunedGame(v *tuned) *Game {
+	g := &Game{}
+	g.RegisterTunable(NewVar("speed", "", &v.speed, 10).Limit(0, 100))
+	g.RegisterTunable(NewVar("count", "", &v.count, 1).Limit(1, 5))
+	g.RegisterTunable(NewVar("enabled", "", &v.enabled, false))
+	g.RegisterTunable(NewVar("cooldown", "", &v.cooldown, 50*time.Millisecond).Limit(0, float64(time.Second)))
+	return g
+}
+
+func TestTunablesSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tuning.json")
+	saved := tuned{speed: 42.5, count: 3, enabled: true, cooldown: 250 * time.Millisecond}
+	if err := newTunedGame(&saved).SaveTunables(path); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if !strings.Contains(string(b), `"cooldown": "250ms"`) {
+		t.Errorf("durations should be saved as strings, got:\n%s", b)
+	}
+
+	var loaded tuned
+	if err = newTunedGame(&loaded).LoadTunables(path); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if loaded != saved {
+		t.Errorf("loaded %+v, saved %+v", loaded, saved)
+	}
+}
+
+func TestTunablesLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		want    tuned
+		wantErr string
+	}{
+		{
+			name: "duration strings",
+			file: `{"cooldown": "300ms"}`,
+			want: tuned{cooldown: 300 * time.Millisecond},
+		},
+		{
+			name: "numbers and booleans",
+			file: `{"speed": 12.5, "count": 2, "enabled": true}`,
+			want: tuned{speed: 12.5, count: 2, enabled: true},
+		},
+		{
+			name: "clamped to limits",
+			file: `{"speed": 1000, "count": -3, "cooldown": "-1s"}`,
+			want: tuned{speed: 100, count: 1},
+		},
+		{
+			name:    "NaN rejected",
+			file:    `{"speed": "NaN"}`,
+			wantErr: "not a finite number",
+		},
+		{
+			name:    "infinity rejected",
+			file:    `{"speed": "+Inf"}`,
+			wantErr: "not a finite number",
+		},
+		{
+			name:    "unknown names reported, the rest applied",
+			file:    `{"warp": 9, "count": 4}`,
+			want:    tuned{count: 4},
+			wantErr: "unknown tunable warp",
+		},
+		{
+			name:    "bad duration",
+			file:    `{"cooldown": "soon"}`,
+			wantErr: "cooldown",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tuning.json")
+			if err := os.WriteFile(path, []byte(tt.file), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			var got tuned
+			err := newTunedGame(&got).LoadTunables(path)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("error %v, want one containing %q", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTunableNudgeReset(t *testing.T) {
+	v := tuned{speed: 95, count: 5}
+	g := newTunedGame(&v)
+	g.Tunable("speed").Nudge(1)
+	g.Tunable("count").Nudge(1)
+	g.Tunable("enabled").Nudge(1)
+	if v.speed != 100 || v.count != 5 || !v.enabled {
+		t.Errorf("nudged to %+v, want speed and count held at limits and enabled flipped", v)
+	}
+	for _, tt := range g.Tunables {
+		tt.Reset()
+	}
+	if want := (tuned{speed: 95, count: 5}); v != want {
+		t.Errorf("reset to %+v, want %+v", v, want)
+	}
+}
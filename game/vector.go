@@ -25,6 +25,10 @@ func (v Vector) Minus(a Vector) Vector {
 	return Vector{X: v.X - a.X, Y: v.Y - a.Y}
 }
 
+func (v Vector) Plus(a Vector) Vector {
+	return Vector{X: v.X + a.X, Y: v.Y + a.Y}
+}
+
 func (v Vector) OrtogonalLeft() Vector {
 	return Vector{X: -v.Y, Y: v.X}
 }
@@ -41,15 +45,27 @@ func (v Vector) Normalized() Vector {
 	return Vector{X: v.X / magnitude, Y: v.Y / magnitude}
 }
 
+// AngleTo returns direction from v to a measured clockwise from up, as used by canon and missles.
+func (v Vector) AngleTo(a Vector) float64 {
+	return math.Atan2(a.X-v.X, v.Y-a.Y)
+}
+
 type Box struct {
 	Vertex []Vector
 	Center Vector
 }
 
-func (b Box) DrawBorder(screen *ebiten.Image) {
-	borderColor := &color.RGBA{G: 255}
-	v := b.Vertex
-	for i := 0; i < len(b.Vertex); i++ {
+func (b Box) DrawBorder(screen *ebiten.Image, view ebiten.GeoM) {
+	b.Stroke(screen, view, color.RGBA{G: 255, A: 255})
+}
+
+// Stroke outlines the polygon with thin lines of clr.
+func (b Box) Stroke(screen *ebiten.Image, view ebiten.GeoM, clr color.Color) {
+	v := make([]Vector, len(b.Vertex))
+	for i, vertex := range b.Vertex {
+		v[i].X, v[i].Y = view.Apply(vertex.X, vertex.Y)
+	}
+	for i := 0; i < len(v); i++ {
 		vector.StrokeLine(
 			screen,
 			float32(v[i].X),
@@ -57,7 +73,7 @@ func (b Box) DrawBorder(screen *ebiten.Image) {
 			float32(v[(i+1)%len(v)].X),
 			float32(v[(i+1)%len(v)].Y),
 			1.0,
-			borderColor,
+			clr,
 			false,
 		)
 	}
@@ -89,3 +105,37 @@ func (b Box) IntersectsCircle(c Vector, r float64) bool {
 	}
 	return true
 }
+
+// Contains reports whether p is inside the polygon, which may be concave.
+func (b Box) Contains(p Vector) bool {
+	in := false
+	for i, j := 0, len(b.Vertex)-1; i < len(b.Vertex); j, i = i, i+1 {
+		a, c := b.Vertex[i], b.Vertex[j]
+		if (a.Y > p.Y) != (c.Y > p.Y) && p.X < (c.X-a.X)*(p.Y-a.Y)/(c.Y-a.Y)+a.X {
+			in = !in
+		}
+	}
+	return in
+}
+
+// IntersectsBox reports whether two polygons, convex or not, overlap.
+func (b Box) IntersectsBox(o Box) bool {
+	for i := range b.Vertex {
+		a1, a2 := b.Vertex[i], b.Vertex[(i+1)%len(b.Vertex)]
+		for j := range o.Vertex {
+			if segmentsCross(a1, a2, o.Vertex[j], o.Vertex[(j+1)%len(o.Vertex)]) {
+				return true
+			}
+		}
+	}
+	return len(o.Vertex) > 0 && b.Contains(o.Vertex[0]) || len(b.Vertex) > 0 && o.Contains(b.Vertex[0])
+}
+
+func segmentsCross(a1, a2, b1, b2 Vector) bool {
+	cross := func(o, a, b Vector) float64 {
+		return (a.X-o.X)*(b.Y-o.Y) - (a.Y-o.Y)*(b.X-o.X)
+	}
+	d1, d2 := cross(b1, b2, a1), cross(b1, b2, a2)
+	d3, d4 := cross(a1, a2, b1), cross(a1, a2, b2)
+	return (d1 > 0) != (d2 > 0) && (d3 > 0) != (d4 > 0)
+}
@@ -0,0 +1,42 @@
+package game
+
+import (
+	"math"
+	"time"
+)
+
+// Weapon is how the canon fires.
+type Weapon struct {
+	Name     string
+	Cooldown time.Duration
+	Count    int     // Missles per shot
+	Spread   float64 // Arc covered by the missles of a shot, radians
+}
+
+// Weapons the canon can be armed with, the first one is the default. Every
+// canon tunes its own copy.
+var Weapons = []Weapon{
+	{Name: "canon", Cooldown: time.Second / 2, Count: 1},
+	{Name: "rapid", Cooldown: time.Second / 8, Count: 1},
+	{Name: "spread", Cooldown: time.Second * 2 / 3, Count: 5, Spread: math.Pi / 4},
+}
+
+func (c *CanonSimple) WeaponByName(name string) (Weapon, bool) {
+	for _, w := range c.Weapons {
+		if w.Name == name {
+			return w, true
+		}
+	}
+	return Weapon{}, false
+}
+
+// Angles returns directions of the missles of one shot aimed at aim.
+func (w Weapon) Angles(aim float64) []float64 {
+	return Pattern{Count: w.Count, Spread: w.Spread}.Angles(aim)
+}
+
+// Arm switches the canon to w, ready to fire.
+func (c *CanonSimple) Arm(w Weapon) {
+	c.Weapon = w
+	c.ShootCooldown = NewReadyTimer(w.Cooldown)
+}
@@ -0,0 +1,29 @@
+package game
+
+import "math"
+
+// Wrap maps v into the window as if its opposite edges were glued together.
+func (w Window) Wrap(v Vector) Vector {
+	width, height := float64(w.Width), float64(w.Height)
+	return Vector{
+		X: math.Mod(math.Mod(v.X, width)+width, width),
+		Y: math.Mod(math.Mod(v.Y, height)+height, height),
+	}
+}
+
+// Delta returns the shortest displacement from a to b across window seams.
+func (w Window) Delta(a, b Vector) Vector {
+	width, height := float64(w.Width), float64(w.Height)
+	d := b.Minus(a)
+	d.X -= width * math.Round(d.X/width)
+	d.Y -= height * math.Round(d.Y/height)
+	return d
+}
+
+// Nearest returns the copy of p closest to origin, p itself unless the world wraps.
+func (g *Game) Nearest(origin, p Vector) Vector {
+	if !g.Wrap {
+		return p
+	}
+	return origin.Plus(g.World.Delta(origin, p))
+}
@@ -2,12 +2,14 @@ module github.com/mxpaul/meteorshooter
 
 go 1.24.11
 
-require github.com/hajimehoshi/ebiten/v2 v2.9.7
+require (
+	github.com/ebitengine/oto/v3 v3.4.0
+	github.com/hajimehoshi/ebiten/v2 v2.9.7
+)
 
 require (
 	github.com/ebitengine/gomobile v0.0.0-20250923094054-ea854a63cce1 // indirect
 	github.com/ebitengine/hideconsole v1.0.0 // indirect
-	github.com/ebitengine/oto/v3 v3.4.0 // indirect
 	github.com/ebitengine/purego v0.9.0 // indirect
 	github.com/jezek/xgb v1.1.1 // indirect
 	github.com/jfreymuth/oggvorbis v1.0.5 // indirect
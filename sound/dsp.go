@@ -0,0 +1,261 @@
+package sound
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+type atomicFloat struct{ bits atomic.Uint64 }
+
+func (f *atomicFloat) Load() float64   { return math.Float64frombits(f.bits.Load()) }
+func (f *atomicFloat) Store(v float64) { f.bits.Store(math.Float64bits(v)) }
+
+// BusFX holds effect settings shared by every stream playing on a bus. The
+// game changes them, audio goroutines of the streams read them.
+type BusFX struct {
+	lowPass  atomicFloat // Cutoff in Hz, 0 disables the filter
+	highPass atomicFloat // Cutoff in Hz, 0 disables the filter
+	reverb   atomicFloat // Wet share, 0 disables reverb
+	duck     atomicFloat // Current gain reduction, 0 none, 1 silence
+
+	DuckRelease time.Duration // Time for a full duck to recover
+}
+
+func NewBusFX() *BusFX {
+	return &BusFX{DuckRelease: 1500 * time.Millisecond}
+}
+
+func (fx *BusFX) LowPass() float64  { return fx.lowPass.Load() }
+func (fx *BusFX) HighPass() float64 { return fx.highPass.Load() }
+func (fx *BusFX) Reverb() float64   { return fx.reverb.Load() }
+func (fx *BusFX) Ducking() float64  { return fx.duck.Load() }
+
+func (fx *BusFX) SetLowPass(hz float64)  { fx.lowPass.Store(max(hz, 0)) }
+func (fx *BusFX) SetHighPass(hz float64) { fx.highPass.Store(max(hz, 0)) }
+func (fx *BusFX) SetReverb(wet float64)  { fx.reverb.Store(min(max(wet, 0), 1)) }
+
+// Duck pulls bus loudness down by amount, unless it is ducked deeper already.
+// Ducking recovers by itself in Update.
+func (fx *BusFX) Duck(amount float64) {
+	fx.duck.Store(max(fx.Ducking(), min(max(amount, 0), 1)))
+}
+
+func (fx *BusFX) Update(dt time.Duration) {
+	if d := fx.Ducking(); d > 0 && fx.DuckRelease > 0 {
+		fx.duck.Store(max(d-float64(dt)/float64(fx.DuckRelease), 0))
+	}
+}
+
+func (fx *BusFX) bypass() bool {
+	return fx.LowPass() == 0 && fx.HighPass() == 0 && fx.Reverb() == 0 && fx.Ducking() == 0
+}
+
+// Chain runs 16-bit stereo PCM through bus effects: high-pass, low-pass,
+// reverb and ducking, in this order. Filter and reverb state is its own,
+// settings come from the bus.
+type Chain struct {
+	Source   io.Reader
+	FX       *BusFX
+	rate     float64
+	lowPass  [2]Biquad
+	highPass [2]Biquad
+	lpCutoff float64
+	hpCutoff float64
+	reverb   [2]*Reverb
+	gain     float64
+	buf      []float64
+}
+
+func NewChain(src io.Reader, fx *BusFX, sampleRate int) *Chain {
+	c := &Chain{
+		Source: src,
+		FX:     fx,
+		rate:   float64(sampleRate),
+		gain:   1,
+	}
+	return c
+}
+
+func (c *Chain) Read(p []byte) (int, error) {
+	n, err := c.Source.Read(p[:len(p)/BytesPerSample*BytesPerSample])
+	if rest := n % BytesPerSample; rest != 0 && err == nil {
+		var m int
+		m, err = io.ReadFull(c.Source, p[n:n+BytesPerSample-rest])
+		n += m
+	}
+	if c.FX.bypass() && c.gain == 1 {
+		return n, err
+	}
+
+	frames := n / BytesPerSample
+	if cap(c.buf) < 2*frames {
+		c.buf = make([]float64, 2*frames)
+	}
+	buf := c.buf[:2*frames]
+	for i := range buf {
+		buf[i] = float64(int16(binary.LittleEndian.Uint16(p[2*i:]))) / math.MaxInt16
+	}
+	c.process(buf)
+	for i, v := range buf {
+		binary.LittleEndian.PutUint16(p[2*i:], uint16(int16(min(max(v, -1), 1)*math.MaxInt16)))
+	}
+	return n, err
+}
+
+func (c *Chain) process(buf []float64) {
+	if cutoff := c.FX.HighPass(); cutoff > 0 {
+		if cutoff != c.hpCutoff {
+			c.hpCutoff = cutoff
+			for ch := range c.highPass {
+				c.highPass[ch].SetHighPass(cutoff, c.rate)
+			}
+		}
+		for i := range buf {
+			buf[i] = c.highPass[i%2].Process(buf[i])
+		}
+	}
+
+	if cutoff := c.FX.LowPass(); cutoff > 0 {
+		if cutoff != c.lpCutoff {
+			c.lpCutoff = cutoff
+			for ch := range c.lowPass {
+				c.lowPass[ch].SetLowPass(cutoff, c.rate)
+			}
+		}
+		for i := range buf {
+			buf[i] = c.lowPass[i%2].Process(buf[i])
+		}
+	} else {
+		c.lpCutoff = 0
+	}
+
+	if wet := c.FX.Reverb(); wet > 0 {
+		if c.reverb[0] == nil {
+			c.reverb = [2]*Reverb{NewReverb(c.rate, 0), NewReverb(c.rate, 23)}
+		}
+		for i := range buf {
+			buf[i] = buf[i]*(1-wet) + c.reverb[i%2].Process(buf[i])*wet
+		}
+	}
+
+	// Gain glides towards its target so ducking does not click.
+	target := 1 - c.FX.Ducking()
+	step := 1 / (0.01 * c.rate)
+	for i := 0; i < len(buf); i += 2 {
+		if c.gain < target {
+			c.gain = min(c.gain+step, target)
+		} else if c.gain > target {
+			c.gain = max(c.gain-step, target)
+		}
+		buf[i] *= c.gain
+		buf[i+1] *= c.gain
+	}
+}
+
+func (c *Chain) Seek(offset int64, whence int) (int64, error) {
+	seeker, ok := c.Source.(io.Seeker)
+	if !ok {
+		return 0, fmt.Errorf("chain: source is not seekable: %w", errors.ErrUnsupported)
+	}
+	pos, err := seeker.Seek(offset, whence)
+	if err == nil && !(whence == io.SeekCurrent && offset == 0) {
+		c.lowPass = [2]Biquad{}
+		c.highPass = [2]Biquad{}
+		c.lpCutoff, c.hpCutoff = 0, 0
+		c.reverb = [2]*Reverb{}
+	}
+	return pos, err
+}
+
+// butterworthQ gives filters a flat pass band without a resonance peak.
+const butterworthQ = 1 / math.Sqrt2
+
+// Biquad is a second order IIR filter, coefficients from the RBJ audio EQ cookbook.
+type Biquad struct {
+	b0, b1, b2, a1, a2 float64
+	x1, x2, y1, y2     float64
+}
+
+func (f *Biquad) SetLowPass(cutoff, sampleRate float64) {
+	w := 2 * math.Pi * min(cutoff, sampleRate*0.45) / sampleRate
+	alpha := math.Sin(w) / (2 * butterworthQ)
+	cos := math.Cos(w)
+	a0 := 1 + alpha
+	f.b0 = (1 - cos) / 2 / a0
+	f.b1 = (1 - cos) / a0
+	f.b2 = f.b0
+	f.a1 = -2 * cos / a0
+	f.a2 = (1 - alpha) / a0
+}
+
+func (f *Biquad) SetHighPass(cutoff, sampleRate float64) {
+	w := 2 * math.Pi * min(cutoff, sampleRate*0.45) / sampleRate
+	alpha := math.Sin(w) / (2 * butterworthQ)
+	cos := math.Cos(w)
+	a0 := 1 + alpha
+	f.b0 = (1 + cos) / 2 / a0
+	f.b1 = -(1 + cos) / a0
+	f.b2 = f.b0
+	f.a1 = -2 * cos / a0
+	f.a2 = (1 - alpha) / a0
+}
+
+func (f *Biquad) Process(x float64) float64 {
+	y := f.b0*x + f.b1*f.x1 + f.b2*f.x2 - f.a1*f.y1 - f.a2*f.y2
+	f.x2, f.x1 = f.x1, x
+	f.y2, f.y1 = f.y1, y
+	return y
+}
+
+// Reverb is a small Schroeder reverberator: parallel feedback combs followed
+// by serial allpasses. Spread detunes delays so left and right differ.
+type Reverb struct {
+	combs     []delayLine
+	allpasses []delayLine
+	feedback  float64
+	damp      float64
+}
+
+type delayLine struct {
+	buf   []float64
+	pos   int
+	store float64
+}
+
+func NewReverb(sampleRate float64, spread int) *Reverb {
+	scale := sampleRate / 44100
+	r := &Reverb{feedback: 0.8, damp: 0.25}
+	for _, d := range []int{1116, 1188, 1277, 1356} {
+		r.combs = append(r.combs, delayLine{buf: make([]float64, int(float64(d+spread)*scale))})
+	}
+	for _, d := range []int{556, 441} {
+		r.allpasses = append(r.allpasses, delayLine{buf: make([]float64, int(float64(d+spread)*scale))})
+	}
+	return r
+}
+
+func (r *Reverb) Process(x float64) float64 {
+	var out float64
+	for i := range r.combs {
+		c := &r.combs[i]
+		y := c.buf[c.pos]
+		c.store = y*(1-r.damp) + c.store*r.damp
+		c.buf[c.pos] = x + c.store*r.feedback
+		c.pos = (c.pos + 1) % len(c.buf)
+		out += y
+	}
+	out /= float64(len(r.combs))
+	for i := range r.allpasses {
+		a := &r.allpasses[i]
+		y := a.buf[a.pos]
+		a.buf[a.pos] = out + y*0.5
+		a.pos = (a.pos + 1) % len(a.buf)
+		out = y - out
+	}
+	return out
+}
@@ -0,0 +1,138 @@
+package sound
+
+import (
+	"fmt"
+	"io/fs"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+)
+
+// Stem is one layer of adaptive music. It is silent below FadeIn intensity
+// and plays at full volume from Full intensity on.
+type Stem struct {
+	Path   string
+	Volume float64 // Balance against the other stems, 1 is full volume
+	FadeIn float64
+	Full   float64
+}
+
+type layer struct {
+	stem   Stem
+	file   fs.File
+	player *audio.Player
+	level  float64
+}
+
+// MaxStemDrift is how far a stem may run off the first one before it is put back in sync.
+const MaxStemDrift = 30 * time.Millisecond
+
+// LayeredMusic plays synchronized looping stems whose volumes follow gameplay
+// intensity, so the soundtrack builds up with the action.
+type LayeredMusic struct {
+	Manager   *Manager
+	FS        fs.FS
+	Stems     []Stem
+	Response  time.Duration // Time a layer takes to go from silent to full volume
+	intensity float64
+	layers    []*layer
+}
+
+func NewLayeredMusic(m *Manager, fsys fs.FS, stems []Stem) *LayeredMusic {
+	l := &LayeredMusic{
+		Manager:  m,
+		FS:       fsys,
+		Stems:    stems,
+		Response: 1500 * time.Millisecond,
+	}
+	return l
+}
+
+func (l *LayeredMusic) IsPlaying() bool { return len(l.layers) > 0 }
+
+// Start opens every stem and starts them together. Stems only make sense as a
+// whole, so a stem that fails to load fails all of them.
+func (l *LayeredMusic) Start() error {
+	if l.IsPlaying() {
+		return nil
+	}
+	if l.Manager.IsSilent() {
+		return fmt.Errorf("no audio")
+	}
+	if len(l.Stems) == 0 {
+		return fmt.Errorf("no stems")
+	}
+	for _, stem := range l.Stems {
+		player, file, _, err := OpenTrack(l.Manager, BusMusic, l.FS, Track{Path: stem.Path, Loop: true})
+		if err != nil {
+			l.Stop()
+			return fmt.Errorf("stem %s: %w", stem.Path, err)
+		}
+		l.Manager.Attach(BusMusic, player, 0)
+		l.layers = append(l.layers, &layer{stem: stem, file: file, player: player})
+	}
+	l.levels(1)
+	for _, layer := range l.layers {
+		layer.player.Play()
+	}
+	return nil
+}
+
+func (l *LayeredMusic) Stop() {
+	for _, layer := range l.layers {
+		layer.player.Pause()
+		l.Manager.Detach(layer.player)
+		layer.player.Close()
+		layer.file.Close()
+	}
+	l.layers = nil
+}
+
+func (l *LayeredMusic) Intensity() float64 { return l.intensity }
+
+func (l *LayeredMusic) SetIntensity(v float64) {
+	l.intensity = min(max(v, 0), 1)
+}
+
+// Update moves layer volumes towards current intensity and keeps stems in sync.
+func (l *LayeredMusic) Update(dt time.Duration) {
+	if !l.IsPlaying() {
+		return
+	}
+	step := 1.0
+	if l.Response > 0 {
+		step = float64(dt) / float64(l.Response)
+	}
+	l.levels(step)
+
+	leader := l.layers[0].player.Position()
+	for _, layer := range l.layers[1:] {
+		drift := layer.player.Position() - leader
+		if drift > MaxStemDrift || drift < -MaxStemDrift {
+			layer.player.SetPosition(leader)
+		}
+	}
+}
+
+func (l *LayeredMusic) levels(step float64) {
+	for _, layer := range l.layers {
+		target := smoothstep(layer.stem.FadeIn, layer.stem.Full, l.intensity)
+		if layer.level < target {
+			layer.level = min(layer.level+step, target)
+		} else {
+			layer.level = max(layer.level-step, target)
+		}
+		l.Manager.SetAttachedVolume(layer.player, layer.stem.Volume*layer.level)
+	}
+}
+
+func smoothstep(edge0, edge1, x float64) float64 {
+	if edge1 <= edge0 {
+		if x >= edge0 {
+			return 1
+		}
+		return 0
+	}
+	t := min(max((x-edge0)/(edge1-edge0), 0), 1)
+	return t * t * (3 - 2*t)
+}
@@ -0,0 +1,305 @@
+package sound
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+)
+
+type Bus int
+
+const (
+	BusMusic Bus = iota
+	BusSFX
+	BusUI
+	busCount
+)
+
+func (b Bus) String() string {
+	switch b {
+	case BusMusic:
+		return "music"
+	case BusSFX:
+		return "sfx"
+	case BusUI:
+		return "ui"
+	}
+	return fmt.Sprintf("Bus(%d)", int(b))
+}
+
+// Range is an inclusive interval random values are picked from. Zero Range means exactly 1.
+type Range struct {
+	Min, Max float64
+}
+
+func (r Range) Random() float64 {
+	if r.Min == 0 && r.Max == 0 {
+		return 1
+	}
+	return r.Min + rand.Float64()*(r.Max-r.Min)
+}
+
+// Sound is a decoded effect played through a limited set of reusable voices.
+// Every play picks the next variant and a random pitch and gain, so rapidly
+// repeated effects do not sound robotic.
+type Sound struct {
+	Name      string
+	Bus       Bus
+	Variants  [][]byte // Decoded 16-bit stereo PCM at context sample rate, played round-robin
+	Volume    float64  // Own volume, multiplied by bus and master volume
+	MaxVoices int      // Concurrent voices, the oldest one is stolen above this
+	Pitch     Range    // Playback rate per play, 1 is original pitch
+	Gain      Range    // Loudness per play
+	next      int
+	voices    []*Voice
+	idle      []*Voice
+}
+
+// Voice is a reusable player of a sound with its own pitch and position in stereo field.
+type Voice struct {
+	Player  *audio.Player
+	Source  *bytes.Reader
+	Pitch   *Resampler
+	Spatial *Spatial
+}
+
+// attachment is a long living player, e.g. music, following its bus volume.
+type attachment struct {
+	bus    Bus
+	player *audio.Player
+	volume float64
+}
+
+// Manager mixes sounds through buses. A Manager without Context is a silent
+// sink: everything works except nothing is heard.
+type Manager struct {
+	Context  *audio.Context
+	master   float64
+	volume   [busCount]float64
+	muted    [busCount]bool
+	fx       [busCount]*BusFX
+	sounds   map[string]*Sound
+	attached []attachment
+}
+
+func NewManager(ctx *audio.Context) *Manager {
+	m := &Manager{
+		Context: ctx,
+		master:  1,
+		sounds:  map[string]*Sound{},
+	}
+	for i := range m.volume {
+		m.volume[i] = 1
+		m.fx[i] = NewBusFX()
+	}
+	return m
+}
+
+func NewSilentManager() *Manager {
+	return NewManager(nil)
+}
+
+func (m *Manager) IsSilent() bool { return m.Context == nil }
+
+func (m *Manager) Register(name string, bus Bus, data []byte, maxVoices int) *Sound {
+	return m.RegisterVariants(name, bus, [][]byte{data}, maxVoices)
+}
+
+// RegisterVariants registers a sound recorded several times, empty variants are dropped.
+func (m *Manager) RegisterVariants(name string, bus Bus, variants [][]byte, maxVoices int) *Sound {
+	s := &Sound{
+		Name:      name,
+		Bus:       bus,
+		Volume:    1,
+		MaxVoices: max(maxVoices, 1),
+	}
+	for _, v := range variants {
+		if len(v) > 0 {
+			s.Variants = append(s.Variants, v)
+		}
+	}
+	m.sounds[name] = s
+	return s
+}
+
+func (m *Manager) Sound(name string) *Sound { return m.sounds[name] }
+
+// SetVariants swaps recordings of a registered sound, e.g. after they were
+// edited on disk. Voices pick them up from their next play.
+func (m *Manager) SetVariants(name string, variants [][]byte) {
+	s, ok := m.sounds[name]
+	if !ok {
+		return
+	}
+	s.Variants = s.Variants[:0:0]
+	for _, v := range variants {
+		if len(v) > 0 {
+			s.Variants = append(s.Variants, v)
+		}
+	}
+}
+
+// Play starts a centered voice of a registered sound at full loudness.
+func (m *Manager) Play(name string) *Voice {
+	return m.PlayAt(name, 0, 1)
+}
+
+// PlayAt starts a voice of a registered sound panned from left (-1) to right
+// (1) and scaled by gain. It reuses an idle voice when there is one and steals
+// the oldest voice when the sound is at its limit.
+func (m *Manager) PlayAt(name string, pan, gain float64) *Voice {
+	s, ok := m.sounds[name]
+	if !ok || m.IsSilent() || len(s.Variants) == 0 {
+		return nil
+	}
+	data := s.Variants[s.next%len(s.Variants)]
+	s.next++
+
+	var v *Voice
+	switch {
+	case len(s.voices) >= s.MaxVoices:
+		v = s.voices[0]
+		s.voices = s.voices[1:]
+		v.Player.Pause()
+	case len(s.idle) > 0:
+		v = s.idle[len(s.idle)-1]
+		s.idle = s.idle[:len(s.idle)-1]
+	default:
+		v = &Voice{Source: bytes.NewReader(data)}
+		v.Pitch = NewResampler(v.Source)
+		v.Spatial = NewSpatial(v.Pitch)
+		p, err := m.NewPlayer(s.Bus, v.Spatial)
+		if err != nil {
+			return nil
+		}
+		v.Player = p
+	}
+
+	v.Source.Reset(data)
+	v.Pitch.SetPitch(s.Pitch.Random())
+	if err := v.Player.Rewind(); err != nil {
+		return nil
+	}
+	v.Spatial.SetPan(pan)
+	v.Spatial.SetGain(gain * s.Gain.Random())
+	v.Player.SetVolume(s.Volume * m.Gain(s.Bus))
+	v.Player.Play()
+	s.voices = append(s.voices, v)
+	return v
+}
+
+// NewPlayer creates a player of 16-bit stereo PCM going through bus effects.
+func (m *Manager) NewPlayer(bus Bus, src io.Reader) (*audio.Player, error) {
+	return m.Context.NewPlayer(NewChain(src, m.fx[bus], m.Context.SampleRate()))
+}
+
+// FX returns effect settings of a bus.
+func (m *Manager) FX(bus Bus) *BusFX { return m.fx[bus] }
+
+// Update moves finished voices back to the idle pool and lets effects like
+// ducking progress by dt.
+func (m *Manager) Update(dt time.Duration) {
+	for _, fx := range m.fx {
+		fx.Update(dt)
+	}
+	for _, s := range m.sounds {
+		for i := 0; i < len(s.voices); i++ {
+			if s.voices[i].Player.IsPlaying() {
+				continue
+			}
+			s.idle = append(s.idle, s.voices[i])
+			s.voices = append(s.voices[:i], s.voices[i+1:]...)
+			i--
+		}
+	}
+}
+
+// Voices returns the number of currently playing voices of a sound.
+func (m *Manager) Voices(name string) int {
+	if s, ok := m.sounds[name]; ok {
+		return len(s.voices)
+	}
+	return 0
+}
+
+// Attach makes a long living player follow bus volume and mute state.
+func (m *Manager) Attach(bus Bus, p *audio.Player, volume float64) {
+	m.attached = append(m.attached, attachment{bus: bus, player: p, volume: volume})
+	p.SetVolume(volume * m.Gain(bus))
+}
+
+// SetAttachedVolume changes own volume of an attached player, e.g. to fade it.
+func (m *Manager) SetAttachedVolume(p *audio.Player, volume float64) {
+	for i, a := range m.attached {
+		if a.player == p {
+			m.attached[i].volume = volume
+			p.SetVolume(volume * m.Gain(a.bus))
+			return
+		}
+	}
+}
+
+func (m *Manager) Detach(p *audio.Player) {
+	for i, a := range m.attached {
+		if a.player == p {
+			m.attached = append(m.attached[:i], m.attached[i+1:]...)
+			return
+		}
+	}
+}
+
+// Gain returns the effective volume multiplier of a bus.
+func (m *Manager) Gain(bus Bus) float64 {
+	if m.muted[bus] {
+		return 0
+	}
+	return m.master * m.volume[bus]
+}
+
+func (m *Manager) Master() float64 { return m.master }
+
+func (m *Manager) SetMaster(v float64) {
+	m.master = min(max(v, 0), 1)
+	m.apply()
+}
+
+func (m *Manager) BusVolume(bus Bus) float64 { return m.volume[bus] }
+
+func (m *Manager) SetBusVolume(bus Bus, v float64) {
+	m.volume[bus] = min(max(v, 0), 1)
+	m.apply()
+}
+
+func (m *Manager) Muted(bus Bus) bool { return m.muted[bus] }
+
+func (m *Manager) SetMuted(bus Bus, muted bool) {
+	m.muted[bus] = muted
+	m.apply()
+}
+
+// ToggleMute mutes every bus unless all of them are muted already.
+func (m *Manager) ToggleMute() {
+	all := true
+	for _, muted := range m.muted {
+		all = all && muted
+	}
+	for i := range m.muted {
+		m.muted[i] = !all
+	}
+	m.apply()
+}
+
+func (m *Manager) apply() {
+	for _, a := range m.attached {
+		a.player.SetVolume(a.volume * m.Gain(a.bus))
+	}
+	for _, s := range m.sounds {
+		for _, v := range s.voices {
+			v.Player.SetVolume(s.Volume * m.Gain(s.Bus))
+		}
+	}
+}
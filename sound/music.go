@@ -0,0 +1,233 @@
+package sound
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"math/rand"
+	"path"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/hajimehoshi/ebiten/v2/audio/vorbis"
+	"github.com/hajimehoshi/ebiten/v2/audio/wav"
+)
+
+// BytesPerSample is the size of one 16-bit stereo frame of decoded audio.
+const BytesPerSample = 4
+
+type Track struct {
+	Path      string        // OGG Vorbis or WAV file, streamed while playing
+	Volume    float64       // Balance within the music bus, 1 is full volume
+	Loop      bool          // Repeat between loop points instead of moving to the next track
+	LoopStart time.Duration // Where a loop restarts, the part before it is an intro
+	LoopEnd   time.Duration // Where a loop jumps back, 0 means end of file
+}
+
+type Playlist struct {
+	Tracks  []Track
+	Shuffle bool
+}
+
+// stream is a decoded track ebiten decoders return.
+type stream interface {
+	io.ReadSeeker
+	Length() int64
+}
+
+type musicVoice struct {
+	track  Track
+	file   fs.File
+	player *audio.Player
+	length time.Duration // Play time of a non looping track
+	fade   float64       // Current fade level
+	target float64       // Fade level being approached, 1 fading in, 0 fading out
+}
+
+// Jukebox streams playlists on the music bus, crossfading between tracks and
+// between playlists.
+type Jukebox struct {
+	Manager   *Manager
+	FS        fs.FS
+	Crossfade time.Duration
+	Playlists map[string]Playlist
+	playlist  string
+	order     []int
+	index     int
+	current   *musicVoice
+	fading    []*musicVoice
+}
+
+func NewJukebox(m *Manager, fsys fs.FS, playlists map[string]Playlist) *Jukebox {
+	j := &Jukebox{
+		Manager:   m,
+		FS:        fsys,
+		Crossfade: 2 * time.Second,
+		Playlists: playlists,
+	}
+	return j
+}
+
+func (j *Jukebox) Playlist() string { return j.playlist }
+
+// Play switches to a playlist, crossfading from whatever plays now.
+func (j *Jukebox) Play(name string) {
+	if name == j.playlist {
+		return
+	}
+	j.playlist = name
+	list := j.Playlists[name]
+	j.order = rand.Perm(len(list.Tracks))
+	if !list.Shuffle {
+		for i := range j.order {
+			j.order[i] = i
+		}
+	}
+	j.index = -1
+	j.Next()
+}
+
+// Next crossfades to the following track of the current playlist, skipping
+// tracks that fail to load.
+func (j *Jukebox) Next() {
+	j.fadeOutCurrent()
+	list := j.Playlists[j.playlist]
+	if j.Manager.IsSilent() || len(list.Tracks) == 0 {
+		return
+	}
+	for range list.Tracks {
+		j.index = (j.index + 1) % len(j.order)
+		track := list.Tracks[j.order[j.index]]
+		v, err := j.start(track)
+		if err != nil {
+			log.Printf("WARNING: music track %s skipped: %v", track.Path, err)
+			continue
+		}
+		j.current = v
+		return
+	}
+}
+
+func (j *Jukebox) Stop() {
+	j.fadeOutCurrent()
+	j.playlist = ""
+}
+
+func (j *Jukebox) fadeOutCurrent() {
+	if j.current != nil {
+		j.current.target = 0
+		j.fading = append(j.fading, j.current)
+		j.current = nil
+	}
+}
+
+func (j *Jukebox) start(track Track) (*musicVoice, error) {
+	player, file, length, err := OpenTrack(j.Manager, BusMusic, j.FS, track)
+	if err != nil {
+		return nil, err
+	}
+	v := &musicVoice{track: track, file: file, player: player, length: length, target: 1}
+	j.Manager.Attach(BusMusic, v.player, 0)
+	v.player.Play()
+	return v, nil
+}
+
+// OpenTrack creates a paused player streaming a track through bus effects.
+// Length is the play time of a non looping track and 0 for a looping one.
+// Close the file once the player is not needed anymore.
+func OpenTrack(m *Manager, bus Bus, fsys fs.FS, track Track) (p *audio.Player, f fs.File, length time.Duration, err error) {
+	file, err := fsys.Open(track.Path)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	defer func() {
+		if err != nil {
+			file.Close()
+		}
+	}()
+	src, ok := file.(io.ReadSeeker)
+	if !ok {
+		return nil, nil, 0, fmt.Errorf("file is not seekable")
+	}
+
+	rate := m.Context.SampleRate()
+	var s stream
+	switch path.Ext(track.Path) {
+	case ".ogg":
+		s, err = vorbis.DecodeWithSampleRate(rate, src)
+	case ".wav":
+		s, err = wav.DecodeWithSampleRate(rate, src)
+	default:
+		err = fmt.Errorf("unknown music format")
+	}
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("decode error: %w", err)
+	}
+
+	var r io.Reader = s
+	if track.Loop {
+		intro := DurationBytes(track.LoopStart, rate)
+		end := s.Length()
+		if track.LoopEnd > 0 {
+			end = min(end, DurationBytes(track.LoopEnd, rate))
+		}
+		if intro < 0 || intro >= end {
+			return nil, nil, 0, fmt.Errorf("loop start %v is not before loop end", track.LoopStart)
+		}
+		r = audio.NewInfiniteLoopWithIntro(s, intro, end-intro)
+	} else {
+		length = time.Duration(s.Length()/BytesPerSample) * time.Second / time.Duration(rate)
+	}
+
+	p, err = m.NewPlayer(bus, r)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("player create error: %w", err)
+	}
+	return p, file, length, nil
+}
+
+// Update advances fades by dt and moves on when a track is about to end.
+func (j *Jukebox) Update(dt time.Duration) {
+	step := 1.0
+	if j.Crossfade > 0 {
+		step = float64(dt) / float64(j.Crossfade)
+	}
+
+	if v := j.current; v != nil {
+		j.fade(v, step)
+		if v.length > 0 && v.length-v.player.Position() <= min(j.Crossfade, v.length/2) {
+			j.Next()
+		}
+	}
+	for i := 0; i < len(j.fading); i++ {
+		v := j.fading[i]
+		j.fade(v, step)
+		if v.fade <= 0 || (v.length > 0 && !v.player.IsPlaying()) {
+			j.release(v)
+			j.fading = append(j.fading[:i], j.fading[i+1:]...)
+			i--
+		}
+	}
+}
+
+func (j *Jukebox) fade(v *musicVoice, step float64) {
+	if v.fade < v.target {
+		v.fade = min(v.fade+step, v.target)
+	} else if v.fade > v.target {
+		v.fade = max(v.fade-step, v.target)
+	}
+	j.Manager.SetAttachedVolume(v.player, v.track.Volume*v.fade)
+}
+
+func (j *Jukebox) release(v *musicVoice) {
+	v.player.Pause()
+	j.Manager.Detach(v.player)
+	v.player.Close()
+	v.file.Close()
+}
+
+// DurationBytes converts play time to decoded stream size, aligned to whole samples.
+func DurationBytes(d time.Duration, sampleRate int) int64 {
+	return int64(d.Seconds()*float64(sampleRate)) * BytesPerSample
+}
@@ -0,0 +1,133 @@
+package sound
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync/atomic"
+)
+
+// Resampler plays 16-bit stereo PCM faster or slower, shifting its pitch.
+// Frames in between source frames are linearly interpolated.
+type Resampler struct {
+	Source  io.Reader
+	ratio   atomic.Uint64 // Source frames per output frame
+	in      []byte
+	inPos   int
+	inLen   int
+	a, b    [2]float64 // Source frames around current position
+	pos     float64    // Position between a and b
+	out     int64      // Output bytes produced since start
+	started bool
+	drained bool // Source has no frames after b
+	ended   bool
+	err     error
+}
+
+func NewResampler(src io.Reader) *Resampler {
+	r := &Resampler{Source: src, in: make([]byte, 4096)}
+	r.SetPitch(1)
+	return r
+}
+
+func (r *Resampler) Pitch() float64 { return math.Float64frombits(r.ratio.Load()) }
+
+// SetPitch sets playback rate, 2 is an octave up and 0.5 an octave down.
+func (r *Resampler) SetPitch(pitch float64) {
+	r.ratio.Store(math.Float64bits(min(max(pitch, 0.1), 4)))
+}
+
+func (r *Resampler) frame() (f [2]float64, ok bool) {
+	for r.inLen-r.inPos < BytesPerSample {
+		if r.err != nil {
+			return f, false
+		}
+		copy(r.in, r.in[r.inPos:r.inLen])
+		r.inLen -= r.inPos
+		r.inPos = 0
+		var n int
+		n, r.err = r.Source.Read(r.in[r.inLen:])
+		r.inLen += n
+	}
+	b := r.in[r.inPos : r.inPos+BytesPerSample]
+	f[0] = float64(int16(binary.LittleEndian.Uint16(b[0:2])))
+	f[1] = float64(int16(binary.LittleEndian.Uint16(b[2:4])))
+	r.inPos += BytesPerSample
+	return f, true
+}
+
+func (r *Resampler) Read(p []byte) (int, error) {
+	if !r.started {
+		r.started = true
+		var ok bool
+		if r.a, ok = r.frame(); !ok {
+			r.ended = true
+		}
+		if r.b, ok = r.frame(); !ok {
+			r.b, r.drained = r.a, true
+		}
+	}
+
+	ratio := r.Pitch()
+	n := 0
+	for ; n+BytesPerSample <= len(p) && !r.ended; n += BytesPerSample {
+		for ch := 0; ch < 2; ch++ {
+			v := r.a[ch] + (r.b[ch]-r.a[ch])*r.pos
+			binary.LittleEndian.PutUint16(p[n+2*ch:], uint16(int16(v)))
+		}
+		r.pos += ratio
+		for r.pos >= 1 {
+			r.pos--
+			if r.drained {
+				r.ended = true
+				break
+			}
+			r.a = r.b
+			var ok bool
+			if r.b, ok = r.frame(); !ok {
+				r.b, r.drained = r.a, true
+			}
+		}
+	}
+
+	r.out += int64(n)
+	if r.ended && n == 0 {
+		if r.err == nil || r.err == io.EOF {
+			return 0, io.EOF
+		}
+		return 0, r.err
+	}
+	return n, nil
+}
+
+// Seek jumps to an output position. Positions are measured in output bytes,
+// which only match source positions at original pitch.
+func (r *Resampler) Seek(offset int64, whence int) (int64, error) {
+	seeker, ok := r.Source.(io.Seeker)
+	if !ok {
+		return 0, fmt.Errorf("resampler: source is not seekable: %w", errors.ErrUnsupported)
+	}
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		if offset == 0 {
+			return r.out, nil
+		}
+		offset += r.out
+	default:
+		return 0, fmt.Errorf("resampler: seek from end: %w", errors.ErrUnsupported)
+	}
+	offset = max(offset, 0) / BytesPerSample * BytesPerSample
+	frames := int64(float64(offset/BytesPerSample) * r.Pitch())
+	if _, err := seeker.Seek(frames*BytesPerSample, io.SeekStart); err != nil {
+		return 0, err
+	}
+	r.inPos, r.inLen = 0, 0
+	r.pos = 0
+	r.started, r.drained, r.ended = false, false, false
+	r.err = nil
+	r.out = offset
+	return offset, nil
+}
@@ -0,0 +1,32 @@
+package sound
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ebitengine/oto/v3"
+)
+
+// ProbeTimeout is how long ProbeDevice waits for the device to get ready.
+const ProbeTimeout = 3 * time.Second
+
+// ProbeDevice opens the audio device the way ebiten does and reports whether
+// it works. Ebiten only notices a failing device after the game started and
+// then stops the game, and a process opens the device once, so run the probe
+// in a separate process before creating an audio context.
+func ProbeDevice(sampleRate int) error {
+	ctx, ready, err := oto.NewContext(&oto.NewContextOptions{
+		SampleRate:   sampleRate,
+		ChannelCount: 2,
+		Format:       oto.FormatFloat32LE,
+	})
+	if err != nil {
+		return err
+	}
+	select {
+	case <-ready:
+	case <-time.After(ProbeTimeout):
+		return fmt.Errorf("audio device not ready after %v", ProbeTimeout)
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,79 @@
+package sound
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync/atomic"
+)
+
+// Spatial wraps decoded 16-bit little endian stereo PCM, panning it between
+// speakers and scaling its loudness. Pan and gain can change while the stream
+// plays: the audio goroutine reads them atomically.
+type Spatial struct {
+	Source io.Reader
+	pan    atomic.Uint64
+	gain   atomic.Uint64
+}
+
+func NewSpatial(src io.Reader) *Spatial {
+	s := &Spatial{Source: src}
+	s.SetPan(0)
+	s.SetGain(1)
+	return s
+}
+
+func (s *Spatial) Pan() float64  { return math.Float64frombits(s.pan.Load()) }
+func (s *Spatial) Gain() float64 { return math.Float64frombits(s.gain.Load()) }
+
+// SetPan moves sound from left (-1) through center (0) to right (1).
+func (s *Spatial) SetPan(pan float64) {
+	s.pan.Store(math.Float64bits(min(max(pan, -1), 1)))
+}
+
+func (s *Spatial) SetGain(gain float64) {
+	s.gain.Store(math.Float64bits(max(gain, 0)))
+}
+
+// ChannelGains returns constant power pan gains, normalized so a centered
+// sound keeps its original loudness.
+func (s *Spatial) ChannelGains() (left, right float64) {
+	theta := (s.Pan() + 1) * math.Pi / 4
+	gain := s.Gain()
+	left = min(math.Sqrt2*math.Cos(theta), 1) * gain
+	right = min(math.Sqrt2*math.Sin(theta), 1) * gain
+	return left, right
+}
+
+func (s *Spatial) Read(p []byte) (int, error) {
+	n, err := s.Source.Read(p[:len(p)/BytesPerSample*BytesPerSample])
+	if rest := n % BytesPerSample; rest != 0 && err == nil {
+		// Never hand out half a frame, it would swap channels from now on.
+		var m int
+		m, err = io.ReadFull(s.Source, p[n:n+BytesPerSample-rest])
+		n += m
+	}
+
+	left, right := s.ChannelGains()
+	for i := 0; i+BytesPerSample <= n; i += BytesPerSample {
+		scaleSample(p[i:i+2], left)
+		scaleSample(p[i+2:i+4], right)
+	}
+	return n, err
+}
+
+func (s *Spatial) Seek(offset int64, whence int) (int64, error) {
+	seeker, ok := s.Source.(io.Seeker)
+	if !ok {
+		return 0, fmt.Errorf("spatial: source is not seekable: %w", errors.ErrUnsupported)
+	}
+	return seeker.Seek(offset, whence)
+}
+
+func scaleSample(b []byte, gain float64) {
+	v := float64(int16(binary.LittleEndian.Uint16(b))) * gain
+	v = min(max(v, math.MinInt16), math.MaxInt16)
+	binary.LittleEndian.PutUint16(b, uint16(int16(v)))
+}
@@ -0,0 +1,116 @@
+package synth
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// Presets make a random sound of a kind. The same rng state makes the same sound.
+var Presets = map[string]func(r *rand.Rand) Params{
+	"pickup":    Pickup,
+	"laser":     Laser,
+	"explosion": Explosion,
+	"powerup":   PowerUp,
+	"hit":       Hit,
+	"blip":      Blip,
+}
+
+func PresetNames() []string {
+	names := make([]string, 0, len(Presets))
+	for name := range Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func between(r *rand.Rand, lo, hi float64) float64 { return lo + r.Float64()*(hi-lo) }
+
+func Pickup(r *rand.Rand) Params {
+	return Params{
+		Wave:      Square,
+		Frequency: between(r, 800, 1600),
+		Duty:      between(r, 0.3, 0.5),
+		Sustain:   between(r, 0.02, 0.06),
+		Punch:     between(r, 0.3, 0.6),
+		Decay:     between(r, 0.1, 0.25),
+		Slide:     between(r, 0, 2),
+		Volume:    DefaultVolume,
+		Seed:      r.Int63(),
+	}
+}
+
+func Laser(r *rand.Rand) Params {
+	return Params{
+		Wave:         Waveform(r.Intn(2)),
+		Frequency:    between(r, 1000, 2500),
+		MinFrequency: between(r, 100, 300),
+		Slide:        -between(r, 6, 14),
+		Duty:         between(r, 0.2, 0.6),
+		DutySweep:    between(r, -1, 1),
+		Sustain:      between(r, 0.05, 0.15),
+		Decay:        between(r, 0.05, 0.2),
+		LowPass:      between(r, 3000, 9000),
+		Volume:       DefaultVolume,
+		Seed:         r.Int63(),
+	}
+}
+
+func Explosion(r *rand.Rand) Params {
+	return Params{
+		Wave:         Noise,
+		Frequency:    between(r, 60, 400),
+		Slide:        -between(r, 0.5, 3),
+		VibratoDepth: between(r, 0, 0.3),
+		VibratoSpeed: between(r, 5, 20),
+		Sustain:      between(r, 0.1, 0.3),
+		Punch:        between(r, 0.3, 0.8),
+		Decay:        between(r, 0.3, 0.7),
+		LowPass:      between(r, 1500, 5000),
+		LowPassSweep: -between(r, 0.5, 2),
+		Volume:       DefaultVolume,
+		Seed:         r.Int63(),
+	}
+}
+
+func PowerUp(r *rand.Rand) Params {
+	return Params{
+		Wave:         Waveform(r.Intn(2)),
+		Frequency:    between(r, 200, 500),
+		Duty:         DefaultDuty,
+		Slide:        between(r, 2, 5),
+		VibratoDepth: between(r, 0.05, 0.2),
+		VibratoSpeed: between(r, 8, 16),
+		Sustain:      between(r, 0.15, 0.35),
+		Decay:        between(r, 0.1, 0.3),
+		Volume:       DefaultVolume,
+		Seed:         r.Int63(),
+	}
+}
+
+func Hit(r *rand.Rand) Params {
+	return Params{
+		Wave:      Waveform(r.Intn(4)),
+		Frequency: between(r, 200, 900),
+		Duty:      DefaultDuty,
+		Slide:     -between(r, 4, 10),
+		Sustain:   between(r, 0.01, 0.05),
+		Decay:     between(r, 0.05, 0.15),
+		LowPass:   between(r, 2000, 6000),
+		Volume:    DefaultVolume,
+		Seed:      r.Int63(),
+	}
+}
+
+func Blip(r *rand.Rand) Params {
+	return Params{
+		Wave:      Waveform(r.Intn(2)),
+		Frequency: between(r, 600, 1400),
+		Duty:      between(r, 0.25, 0.5),
+		Sustain:   between(r, 0.02, 0.05),
+		Decay:     between(r, 0.01, 0.04),
+		LowPass:   between(r, 4000, 10000),
+		Volume:    DefaultVolume,
+		Seed:      r.Int63(),
+	}
+}
@@ -0,0 +1,198 @@
+// Package synth renders retro sound effects from a handful of parameters, the
+// way sfxr does: an oscillator with frequency slide and vibrato, shaped by an
+// envelope and smoothed by a low-pass filter.
+package synth
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+type Waveform int
+
+const (
+	Square Waveform = iota
+	Sawtooth
+	Sine
+	Noise
+)
+
+func (w Waveform) String() string {
+	switch w {
+	case Square:
+		return "square"
+	case Sawtooth:
+		return "sawtooth"
+	case Sine:
+		return "sine"
+	case Noise:
+		return "noise"
+	}
+	return fmt.Sprintf("Waveform(%d)", int(w))
+}
+
+// Params describe a sound. Times are in seconds, frequencies in Hz.
+type Params struct {
+	Wave         Waveform `json:"wave"`
+	Frequency    float64  `json:"frequency"`     // Start frequency
+	MinFrequency float64  `json:"min_frequency"` // Sound stops when sliding below it
+	Slide        float64  `json:"slide"`         // Frequency change, octaves per second
+	DeltaSlide   float64  `json:"delta_slide"`   // Slide change, octaves per second squared
+	Duty         float64  `json:"duty"`          // Square wave duty cycle, DefaultDuty is even
+	DutySweep    float64  `json:"duty_sweep"`    // Duty change per second
+	VibratoDepth float64  `json:"vibrato_depth"` // Share of frequency
+	VibratoSpeed float64  `json:"vibrato_speed"`
+	Attack       float64  `json:"attack"`
+	Sustain      float64  `json:"sustain"`
+	Punch        float64  `json:"punch"` // Extra loudness at sustain start, fading during sustain
+	Decay        float64  `json:"decay"`
+	LowPass      float64  `json:"low_pass"`       // Cutoff frequency, 0 disables the filter
+	LowPassSweep float64  `json:"low_pass_sweep"` // Cutoff change, octaves per second
+	Volume       float64  `json:"volume"`         // Loudness, leaving headroom at DefaultVolume
+	Seed         int64    `json:"seed"`           // Noise seed
+}
+
+// Limits of what Render accepts.
+const (
+	MaxDuration   = 60     // Seconds
+	MaxSampleRate = 192000 // Hz
+)
+
+// Defaults of parameters the presets and NewParams start from.
+const (
+	DefaultDuty   = 0.5
+	DefaultVolume = 0.5
+)
+
+// NewParams returns a silent sound with default duty and volume, a base for
+// parameters read from JSON.
+func NewParams() Params {
+	return Params{Duty: DefaultDuty, Volume: DefaultVolume}
+}
+
+// Duration returns sound length in seconds.
+func (p Params) Duration() float64 { return p.Attack + p.Sustain + p.Decay }
+
+// Validate reports parameters Render can not make a sound of.
+func (p Params) Validate(sampleRate int) error {
+	if sampleRate <= 0 || sampleRate > MaxSampleRate {
+		return fmt.Errorf("sample rate %d out of 1..%d", sampleRate, MaxSampleRate)
+	}
+	for _, v := range []struct {
+		name  string
+		value float64
+	}{
+		{"frequency", p.Frequency}, {"min_frequency", p.MinFrequency},
+		{"slide", p.Slide}, {"delta_slide", p.DeltaSlide},
+		{"duty", p.Duty}, {"duty_sweep", p.DutySweep},
+		{"vibrato_depth", p.VibratoDepth}, {"vibrato_speed", p.VibratoSpeed},
+		{"attack", p.Attack}, {"sustain", p.Sustain}, {"punch", p.Punch}, {"decay", p.Decay},
+		{"low_pass", p.LowPass}, {"low_pass_sweep", p.LowPassSweep}, {"volume", p.Volume},
+	} {
+		if math.IsNaN(v.value) || math.IsInf(v.value, 0) {
+			return fmt.Errorf("%s is not a finite number", v.name)
+		}
+	}
+	if p.Attack < 0 || p.Sustain < 0 || p.Decay < 0 {
+		return fmt.Errorf("attack, sustain and decay must not be negative")
+	}
+	if p.Duration() > MaxDuration {
+		return fmt.Errorf("duration %.2fs is longer than %ds", p.Duration(), MaxDuration)
+	}
+	return nil
+}
+
+// Render synthesizes mono samples in [-1, 1].
+func (p Params) Render(sampleRate int) ([]float64, error) {
+	if err := p.Validate(sampleRate); err != nil {
+		return nil, err
+	}
+	rate := float64(sampleRate)
+	out := make([]float64, int(p.Duration()*rate))
+	rng := rand.New(rand.NewSource(p.Seed))
+
+	var noise [32]float64
+	for i := range noise {
+		noise[i] = rng.Float64()*2 - 1
+	}
+
+	freq := p.Frequency
+	phase := 0.0
+	filtered := 0.0
+	for i := range out {
+		t := float64(i) / rate
+
+		freq *= math.Pow(2, (p.Slide+p.DeltaSlide*t)/rate)
+		if p.MinFrequency > 0 && freq < p.MinFrequency {
+			return out[:i], nil
+		}
+		f := freq * (1 + p.VibratoDepth*math.Sin(2*math.Pi*p.VibratoSpeed*t))
+
+		phase += f / rate
+		if phase >= 1 {
+			phase -= math.Floor(phase)
+			if p.Wave == Noise {
+				for j := range noise {
+					noise[j] = rng.Float64()*2 - 1
+				}
+			}
+		}
+
+		v := p.oscillate(phase, t, noise[:])
+		if p.LowPass > 0 {
+			cutoff := min(p.LowPass*math.Pow(2, p.LowPassSweep*t), rate/2)
+			alpha := 1 - math.Exp(-2*math.Pi*cutoff/rate)
+			filtered += alpha * (v - filtered)
+			v = filtered
+		}
+		out[i] = v * p.envelope(t) * p.Volume
+	}
+	return out, nil
+}
+
+func (p Params) oscillate(phase, t float64, noise []float64) float64 {
+	switch p.Wave {
+	case Square:
+		duty := min(max(p.Duty+p.DutySweep*t, 0.05), 0.95)
+		if phase < duty {
+			return 1
+		}
+		return -1
+	case Sawtooth:
+		return 1 - 2*phase
+	case Sine:
+		return math.Sin(2 * math.Pi * phase)
+	case Noise:
+		return noise[int(phase*float64(len(noise)))%len(noise)]
+	}
+	return 0
+}
+
+func (p Params) envelope(t float64) float64 {
+	switch {
+	case t < p.Attack:
+		return t / p.Attack
+	case t < p.Attack+p.Sustain:
+		return 1 + p.Punch*(1-(t-p.Attack)/p.Sustain)
+	case p.Decay > 0:
+		return max(1-(t-p.Attack-p.Sustain)/p.Decay, 0)
+	}
+	return 0
+}
+
+// PCM16Stereo converts mono samples to 16-bit little endian stereo PCM, the
+// format ebiten audio players take.
+func PCM16Stereo(samples []float64) []byte {
+	b := make([]byte, 4*len(samples))
+	for i, s := range samples {
+		v := uint16(toInt16(s))
+		b[4*i], b[4*i+1] = byte(v), byte(v>>8)
+		b[4*i+2], b[4*i+3] = byte(v), byte(v>>8)
+	}
+	return b
+}
+
+func toInt16(s float64) int16 {
+	return int16(min(max(s, -1), 1) * math.MaxInt16)
+}